@@ -0,0 +1,124 @@
+package aerospace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Capability is an Aerospace feature that only some releases support
+type Capability int
+
+const (
+	CapFormat              Capability = iota // --format on list commands, with %{monitor-name}
+	CapMouseMonitor                          // list-monitors --mouse
+	CapWorkspaceVisibility                   // %{workspace-is-focused} and %{workspace-is-visible}
+	CapMoveWorkspaceFlag                     // move-workspace-to-monitor --workspace
+	CapMonitorMetadata                       // %{monitor-is-main} and %{monitor-appkit-nsscreen-screens-id}
+	CapJSON                                  // --json on list commands
+)
+
+// capabilityInfo describes a capability and the read-only probe telling
+// whether the installed Aerospace has it. Which release introduced a feature
+// is not relied upon: queries use the richest form first and fall back when
+// Aerospace rejects it, probes only run to explain a query that failed. JSON
+// output and monitor metadata have no probe, lacking them is not an error.
+var capabilityInfo = map[Capability]struct {
+	feature string
+	probe   probe
+}{
+	CapFormat:              {"list --format", probe{args: []string{"list-monitors", "--format", "%{monitor-id}|%{monitor-name}"}}},
+	CapMouseMonitor:        {"list-monitors --mouse", probe{args: []string{"list-monitors", "--mouse"}}},
+	CapWorkspaceVisibility: {"%{workspace-is-visible} format variables", probe{args: []string{"list-workspaces", "--focused", "--format", "%{workspace-is-focused} %{workspace-is-visible}"}}},
+	CapMoveWorkspaceFlag:   {"move-workspace-to-monitor --workspace", probe{args: []string{"move-workspace-to-monitor", "--help"}, usage: "--workspace"}},
+}
+
+// probe is a read-only command that fails on releases without a feature.
+// Commands that can't be run harmlessly are probed through --help instead,
+// the feature is there when the usage mentions it, whatever the exit status.
+type probe struct {
+	args  []string
+	usage string // Text the output must contain, empty when succeeding is enough
+}
+
+// ErrUnsupported is matched by every *UnsupportedError
+var ErrUnsupported = errors.New("unsupported by the installed aerospace")
+
+// UnsupportedError reports a feature the installed Aerospace lacks
+type UnsupportedError struct {
+	Feature   string  // Human readable name of the feature
+	Installed Version // Detected release, zero when unknown
+	Err       error   // Failure that led to probing for the feature
+}
+
+func (e *UnsupportedError) Error() string {
+	installed := "the installed aerospace"
+	if e.Installed != (Version{}) {
+		installed = "aerospace " + e.Installed.String()
+	}
+	return fmt.Sprintf("%s does not support %s: %v", installed, e.Feature, e.Err)
+}
+
+func (e *UnsupportedError) Unwrap() []error {
+	return []error{ErrUnsupported, e.Err}
+}
+
+// has reports whether the capability is worth trying, that is it wasn't
+// found missing by an earlier query of this client
+func (c *Client) has(capability Capability) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.missing[capability]
+}
+
+// lacks remembers a capability found missing for the lifetime of the client
+func (c *Client) lacks(capability Capability) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.missing == nil {
+		c.missing = make(map[Capability]bool)
+	}
+	c.missing[capability] = true
+}
+
+// rejected reports whether a query failed in a way an Aerospace release
+// without the feature would: a command error nothing else explains, or
+// output that can't be parsed
+func rejected(err error) bool {
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Kind == nil
+	}
+	var parseErr *ParseError
+	return errors.As(err, &parseErr)
+}
+
+// explain is called with the error of a failed query or command. It probes
+// the given capabilities and returns an *UnsupportedError for the first one
+// missing, or err itself when the failure has another cause.
+func (c *Client) explain(ctx context.Context, err error, capabilities ...Capability) error {
+	if !rejected(err) {
+		return err
+	}
+	for _, capability := range capabilities {
+		info := capabilityInfo[capability]
+		stdout, stderr, probeErr := c.runner.Run(ctx, info.probe.args...)
+		output := combinedOutput(stdout, stderr)
+		if probeErr != nil && !rejected(commandError(info.probe.args, output, probeErr)) {
+			// The probe failed for the same reason as everything else, like a stopped server
+			return err
+		}
+
+		supported := probeErr == nil
+		if info.probe.usage != "" {
+			supported = strings.Contains(output, info.probe.usage)
+		}
+		if !supported {
+			c.lacks(capability)
+			installed, _ := c.Version(ctx)
+			return &UnsupportedError{Feature: info.feature, Installed: installed, Err: err}
+		}
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package aerospace
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestListMonitorsFallback(t *testing.T) {
+	runner := &cannedRunner{outputs: map[string]string{
+		"list-monitors --format %{monitor-id}|%{monitor-name}": "2|DELL U2720Q\n1|Built-in Retina Display\n",
+	}}
+	client := NewClient(runner)
+
+	monitors, err := client.ListMonitors(t.Context())
+	if err != nil {
+		t.Fatalf("ListMonitors() error = %v", err)
+	}
+	expected := []Monitor{{ID: 1, Name: "Built-in Retina Display"}, {ID: 2, Name: "DELL U2720Q"}}
+	if !reflect.DeepEqual(monitors, expected) {
+		t.Errorf("ListMonitors() = %+v, expected %+v", monitors, expected)
+	}
+
+	// The rejected queries are remembered, the next call goes straight to the basic one
+	calls := len(runner.calls)
+	if _, err := client.ListMonitors(t.Context()); err != nil {
+		t.Fatalf("ListMonitors() error = %v", err)
+	}
+	if got := runner.calls[calls:]; len(got) != 1 || !strings.Contains(strings.Join(got[0], " "), "%{monitor-id}|%{monitor-name}") {
+		t.Errorf("ListMonitors() ran %v again, expected only the basic query", got)
+	}
+}
+
+func TestExplainUnsupported(t *testing.T) {
+	runner := &cannedRunner{outputs: map[string]string{
+		"--version": "aerospace CLI client version: 0.11.2-Beta\nAeroSpace.app server version: 0.11.2-Beta\n",
+		"list-monitors --format %{monitor-id}|%{monitor-name}": "1|Built-in Retina Display\n",
+		"move-workspace-to-monitor --help":                     "USAGE: move-workspace-to-monitor [-h|--help] (next|prev) [--wrap-around]\n",
+	}}
+	client := NewClient(runner)
+
+	_, err := client.GetMouseMonitorID(t.Context())
+	expected := "aerospace 0.11.2 does not support list-monitors --mouse"
+	if !errors.Is(err, ErrUnsupported) || !strings.HasPrefix(err.Error(), expected) {
+		t.Errorf("GetMouseMonitorID() error = %v, expected it to start with %q", err, expected)
+	}
+
+	err = client.MoveWorkspaceToMonitor(t.Context(), "L1", 2)
+	if !errors.Is(err, ErrUnsupported) || !strings.Contains(err.Error(), "does not support move-workspace-to-monitor --workspace") {
+		t.Errorf("MoveWorkspaceToMonitor() error = %v, expected ErrUnsupported for the --workspace flag", err)
+	}
+}
+
+func TestExplainOtherFailures(t *testing.T) {
+	// A workspace that doesn't exist says nothing about the feature, no probe runs
+	runner := &cannedRunner{}
+	client := NewClient(RunnerFunc(func(ctx context.Context, args ...string) ([]byte, []byte, error) {
+		runner.Run(ctx, args...)
+		return nil, []byte("Workspace 'L9' doesn't exist"), errors.New("exit status 2")
+	}))
+	err := client.MoveWorkspaceToMonitor(t.Context(), "L9", 2)
+	if errors.Is(err, ErrUnsupported) || !errors.Is(err, ErrUnknownWorkspace) || len(runner.calls) != 1 {
+		t.Errorf("MoveWorkspaceToMonitor() error = %v after %d calls, expected ErrUnknownWorkspace without probing", err, len(runner.calls))
+	}
+
+	// Neither does a server that isn't running
+	down := NewClient(RunnerFunc(func(ctx context.Context, args ...string) ([]byte, []byte, error) {
+		return nil, []byte("Can't connect to AeroSpace server. Is AeroSpace.app running?"), errors.New("exit status 1")
+	}))
+	if _, err := down.GetMouseMonitorID(t.Context()); errors.Is(err, ErrUnsupported) || !errors.Is(err, ErrServerNotRunning) {
+		t.Errorf("GetMouseMonitorID() error = %v, expected ErrServerNotRunning", err)
+	}
+}
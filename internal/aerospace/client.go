@@ -0,0 +1,126 @@
+package aerospace
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Runner executes a single aerospace command and returns what it wrote to
+// stdout and stderr. A non-nil error means the command could not be started
+// or exited unsuccessfully. Runners must give up once ctx is done and must be
+// safe for concurrent use.
+type Runner interface {
+	Run(ctx context.Context, args ...string) (stdout, stderr []byte, err error)
+}
+
+// RunnerFunc adapts an ordinary function to the Runner interface
+type RunnerFunc func(ctx context.Context, args ...string) (stdout, stderr []byte, err error)
+
+// Run calls f(ctx, args...)
+func (f RunnerFunc) Run(ctx context.Context, args ...string) ([]byte, []byte, error) {
+	return f(ctx, args...)
+}
+
+// ExecRunner runs commands by spawning the aerospace CLI
+type ExecRunner struct {
+	Path string // Path to the aerospace binary, looked up in PATH when empty
+}
+
+// Run spawns the aerospace CLI with the given arguments, killing it when ctx is done
+func (r ExecRunner) Run(ctx context.Context, args ...string) ([]byte, []byte, error) {
+	path := r.Path
+	if path == "" {
+		path = "aerospace"
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait forever for pipes held open by anything the CLI left behind
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+	if err != nil && ctx.Err() != nil {
+		err = fmt.Errorf("%w: %v", ctx.Err(), err)
+	}
+
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+// Client issues Aerospace queries and commands through a Runner
+type Client struct {
+	runner Runner
+
+	versionOnce sync.Once
+	version     Version
+	versionErr  error
+
+	mu       sync.Mutex
+	warnings []*Warning          // Harmless output of commands that succeeded
+	missing  map[Capability]bool // Capabilities the installed Aerospace was found to lack
+}
+
+// NewClient creates a Client that executes commands with the given runner
+func NewClient(runner Runner) *Client {
+	return &Client{runner: runner}
+}
+
+// NewExecClient creates a Client that spawns the aerospace CLI for every call
+func NewExecClient() *Client {
+	return NewClient(ExecRunner{})
+}
+
+// Close releases the resources held by the underlying runner, if any
+func (c *Client) Close() error {
+	if closer, ok := c.runner.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// query runs a read-only command and returns its stdout
+func (c *Client) query(ctx context.Context, args ...string) ([]byte, error) {
+	stdout, stderr, err := c.runner.Run(ctx, args...)
+	if err != nil {
+		return nil, commandError(args, combinedOutput(stdout, stderr), err)
+	}
+	return stdout, nil
+}
+
+// action runs a command that is expected to print nothing on success.
+// Output consisting only of known harmless warnings still succeeds, the
+// warning is kept for Warnings.
+func (c *Client) action(ctx context.Context, args ...string) error {
+	stdout, stderr, err := c.runner.Run(ctx, args...)
+	output := combinedOutput(stdout, stderr)
+	if err != nil {
+		return commandError(args, output, err)
+	}
+	warning, err := classifyOutput(args, output)
+	if warning != nil {
+		c.mu.Lock()
+		c.warnings = append(c.warnings, warning)
+		c.mu.Unlock()
+	}
+	return err
+}
+
+// Warnings returns the harmless warnings printed by the commands that
+// succeeded so far, oldest first
+func (c *Client) Warnings() []*Warning {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.warnings)
+}
+
+// combinedOutput joins stdout and stderr into trimmed text
+func combinedOutput(stdout, stderr []byte) string {
+	return strings.TrimSpace(string(stdout) + string(stderr))
+}
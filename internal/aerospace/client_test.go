@@ -0,0 +1,102 @@
+package aerospace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cannedRunner answers commands from a table keyed by the space-joined
+// arguments and records every call it receives
+type cannedRunner struct {
+	mu      sync.Mutex
+	outputs map[string]string
+	calls   [][]string
+}
+
+func (r *cannedRunner) Run(ctx context.Context, args ...string) ([]byte, []byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, args)
+	out, ok := r.outputs[strings.Join(args, " ")]
+	if !ok {
+		return nil, []byte("unknown command"), errors.New("exit status 2")
+	}
+	return []byte(out), nil, nil
+}
+
+// jsonVersionOutput is what an Aerospace release with --json support prints for --version
+const jsonVersionOutput = "aerospace CLI client version: 0.18.5-Beta 8d2e2f4\nAeroSpace.app server version: 0.18.5-Beta 8d2e2f4\n"
+
+func TestMoveWorkspaceToMonitor(t *testing.T) {
+	runner := &cannedRunner{outputs: map[string]string{
+		"move-workspace-to-monitor --workspace L1 2": "",
+	}}
+	client := NewClient(runner)
+
+	if err := client.MoveWorkspaceToMonitor(t.Context(), "L1", 2); err != nil {
+		t.Fatalf("MoveWorkspaceToMonitor() error = %v", err)
+	}
+
+	expected := [][]string{{"move-workspace-to-monitor", "--workspace", "L1", "2"}}
+	if !reflect.DeepEqual(runner.calls, expected) {
+		t.Errorf("MoveWorkspaceToMonitor() ran %v, expected %v", runner.calls, expected)
+	}
+}
+
+func TestMoveNodeToWorkspace(t *testing.T) {
+	runner := &cannedRunner{outputs: map[string]string{
+		"move-node-to-workspace --focus-follows-window B2": "",
+	}}
+	client := NewClient(runner)
+
+	if err := client.MoveNodeToWorkspace(t.Context(), "B2", true); err != nil {
+		t.Fatalf("MoveNodeToWorkspace() error = %v", err)
+	}
+	if err := client.MoveNodeToWorkspace(t.Context(), "B2", false); err == nil {
+		t.Errorf("MoveNodeToWorkspace() without focus-follows ran an unexpected command")
+	}
+}
+
+func TestSwitchWorkspaceUnexpectedOutput(t *testing.T) {
+	runner := &cannedRunner{outputs: map[string]string{
+		"workspace R1": "Something went sideways",
+	}}
+	client := NewClient(runner)
+
+	err := client.SwitchWorkspace(t.Context(), "R1")
+	if !errors.Is(err, ErrUnexpectedOutput) {
+		t.Errorf("SwitchWorkspace() error = %v, expected ErrUnexpectedOutput", err)
+	}
+	if len(client.Warnings()) != 0 {
+		t.Errorf("Warnings() = %v, expected a failure rather than a warning", client.Warnings())
+	}
+}
+
+func TestExecRunnerTimeout(t *testing.T) {
+	// Stand in for an aerospace CLI stuck waiting on a hung server
+	path := filepath.Join(t.TempDir(), "aerospace")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nexec sleep 10\n"), 0o755); err != nil {
+		t.Fatalf("failed to write fake aerospace: %v", err)
+	}
+	client := NewClient(ExecRunner{Path: path})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := client.SwitchWorkspace(ctx, "B1")
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("SwitchWorkspace() error = %v, expected ErrTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("SwitchWorkspace() took %v to time out", elapsed)
+	}
+}
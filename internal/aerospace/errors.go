@@ -0,0 +1,146 @@
+package aerospace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Sentinel errors classifying Aerospace failures, for use with errors.Is
+var (
+	ErrServerNotRunning = errors.New("aerospace server is not running")
+	ErrUnknownWorkspace = errors.New("unknown workspace")
+	ErrUnknownMonitor   = errors.New("unknown monitor")
+	ErrUnexpectedOutput = errors.New("unexpected output")
+	ErrTimeout          = errors.New("aerospace call timed out")
+)
+
+// failurePatterns recognize the failure kind from what aerospace printed
+var failurePatterns = []struct {
+	pattern *regexp.Regexp
+	kind    error
+}{
+	{regexp.MustCompile(`(?i)can't connect to aerospace server|is aerospace\.app running`), ErrServerNotRunning},
+	{regexp.MustCompile(`(?i)workspace .*(doesn't|does not) exist|unknown workspace|invalid workspace name`), ErrUnknownWorkspace},
+	{regexp.MustCompile(`(?i)monitor .*(doesn't|does not) exist|can't find monitor|unknown monitor|no monitors? match`), ErrUnknownMonitor},
+}
+
+// harmlessWarnings match output lines that don't indicate a failed command
+var harmlessWarnings = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bis already (focused|on|assigned)\b`),
+	regexp.MustCompile(`(?i)^warning:`),
+	regexp.MustCompile(`(?i)\bdeprecated\b`),
+}
+
+// CommandError reports an aerospace command that failed or printed unexpected output
+type CommandError struct {
+	Args   []string // Arguments the command was run with
+	Output string   // What the command printed
+	Kind   error    // Sentinel classifying the failure, nil when unrecognized
+	Err    error    // Error reported by the runner, nil for unexpected output
+}
+
+func (e *CommandError) Error() string {
+	command := strings.Join(e.Args, " ")
+	switch {
+	case e.Err == nil:
+		return fmt.Sprintf("aerospace %s: %v: %s", command, e.Kind, e.Output)
+	case e.Output == "":
+		return fmt.Sprintf("aerospace %s: %v", command, e.Err)
+	default:
+		return fmt.Sprintf("aerospace %s: %v (output: %s)", command, e.Err, e.Output)
+	}
+}
+
+// Unwrap exposes both the failure kind and the runner error to errors.Is and errors.As
+func (e *CommandError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// ParseError reports aerospace output that could not be parsed
+type ParseError struct {
+	Command string // Aerospace command that produced the output
+	Line    string // Offending line of output
+	Err     error  // What was wrong with it
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid aerospace %s output %q: %v", e.Command, e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Warning reports harmless output from a command that otherwise succeeded
+type Warning struct {
+	Args    []string // Arguments the command was run with
+	Message string   // What the command printed
+}
+
+func (w *Warning) Error() string {
+	return fmt.Sprintf("aerospace %s: warning: %s", strings.Join(w.Args, " "), w.Message)
+}
+
+// commandError classifies a failed command by its output
+func commandError(args []string, output string, err error) error {
+	cmdErr := &CommandError{Args: args, Output: output, Err: err}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		cmdErr.Kind = ErrTimeout
+		return cmdErr
+	}
+	for _, fp := range failurePatterns {
+		if fp.pattern.MatchString(output) {
+			cmdErr.Kind = fp.kind
+			break
+		}
+	}
+
+	return cmdErr
+}
+
+// classifyOutput inspects the output of a command that is expected to print
+// nothing, returning a *Warning when it only printed harmless warnings and an
+// unexpected output error when it printed anything else
+func classifyOutput(args []string, output string) (*Warning, error) {
+	if output == "" {
+		return nil, nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if !isHarmless(line) {
+			err := commandError(args, output, nil)
+			if cmdErr := err.(*CommandError); cmdErr.Kind == nil {
+				cmdErr.Kind = ErrUnexpectedOutput
+			}
+			return nil, err
+		}
+	}
+
+	return &Warning{Args: args, Message: output}, nil
+}
+
+// isHarmless reports whether an output line is a known harmless warning
+func isHarmless(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return true
+	}
+	for _, pattern := range harmlessWarnings {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
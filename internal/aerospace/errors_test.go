@@ -0,0 +1,71 @@
+package aerospace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestCommandErrorKinds(t *testing.T) {
+	tests := []struct {
+		output   string
+		err      error
+		expected error
+	}{
+		{output: "Can't connect to AeroSpace server. Is AeroSpace.app running?", err: errors.New("exit status 1"), expected: ErrServerNotRunning},
+		{output: "Workspace 'X9' doesn't exist", err: errors.New("exit status 2"), expected: ErrUnknownWorkspace},
+		{output: "Can't find monitor 7", err: errors.New("exit status 2"), expected: ErrUnknownMonitor},
+		{output: "", err: fmt.Errorf("read: %w", os.ErrDeadlineExceeded), expected: ErrTimeout},
+	}
+
+	for _, tt := range tests {
+		client := NewClient(RunnerFunc(func(ctx context.Context, args ...string) ([]byte, []byte, error) {
+			return nil, []byte(tt.output), tt.err
+		}))
+
+		err := client.MoveWorkspaceToMonitor(t.Context(), "X9", 7)
+		if !errors.Is(err, tt.expected) {
+			t.Errorf("MoveWorkspaceToMonitor() with output %q error = %v, expected %v", tt.output, err, tt.expected)
+		}
+
+		var cmdErr *CommandError
+		if !errors.As(err, &cmdErr) {
+			t.Errorf("MoveWorkspaceToMonitor() error = %v, expected a *CommandError", err)
+		} else if !errors.Is(err, tt.err) {
+			t.Errorf("MoveWorkspaceToMonitor() error = %v, expected it to wrap %v", err, tt.err)
+		}
+	}
+}
+
+func TestHarmlessOutputIsWarning(t *testing.T) {
+	client := NewClient(RunnerFunc(func(ctx context.Context, args ...string) ([]byte, []byte, error) {
+		return nil, []byte("Workspace 'B1' is already on the target monitor\n"), nil
+	}))
+
+	if err := client.MoveWorkspaceToMonitor(t.Context(), "B1", 2); err != nil {
+		t.Fatalf("MoveWorkspaceToMonitor() error = %v, expected success with a warning", err)
+	}
+	warnings := client.Warnings()
+	if len(warnings) != 1 || warnings[0].Message != "Workspace 'B1' is already on the target monitor" {
+		t.Errorf("Warnings() = %v, expected the already-on-monitor warning", warnings)
+	}
+}
+
+func TestParseErrorCarriesLine(t *testing.T) {
+	// The metadata query is rejected for its output, the basic one has nothing to fall back to
+	client := NewClient(&cannedRunner{outputs: map[string]string{
+		listMonitorsQuery: "1|true|1|Built-in Retina Display\nfirst|false|2|DELL U2720Q\n",
+		"list-monitors --format %{monitor-id}|%{monitor-name}": "1|Built-in Retina Display\nfirst|DELL U2720Q\n",
+	}})
+
+	_, err := client.ListMonitors(t.Context())
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("ListMonitors() error = %v, expected a *ParseError", err)
+	}
+	if parseErr.Line != "first|DELL U2720Q" {
+		t.Errorf("ParseError.Line = %q, expected %q", parseErr.Line, "first|DELL U2720Q")
+	}
+}
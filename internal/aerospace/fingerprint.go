@@ -0,0 +1,75 @@
+package aerospace
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Fingerprint identifies a monitor across reconnects. macOS numbers
+// identically named monitors " (1)", " (2)" in whatever order they show up, so
+// the name is kept without that suffix and paired with the AppKit screen ID and
+// the rank among the monitors sharing the name.
+// Nothing Aerospace reports is tied to a physical panel: the screen ID and the
+// rank both follow the order macOS enumerates screens in. A fingerprint
+// survives one of them being renumbered, but two identical panels that trade
+// places trade fingerprints too.
+type Fingerprint struct {
+	Name     string `json:"name"`             // Monitor name without the " (n)" suffix
+	AppKitID int    `json:"appkit,omitempty"` // 1-based index into NSScreen.screens, 0 when unknown
+	Position int    `json:"position"`         // 1-based rank by Aerospace monitor ID among monitors sharing the name
+}
+
+// duplicateSuffix is what macOS appends to the names of identical monitors
+var duplicateSuffix = regexp.MustCompile(` \(\d+\)$`)
+
+// BaseName returns the monitor name without the suffix macOS adds to tell
+// identically named monitors apart
+func BaseName(name string) string {
+	return duplicateSuffix.ReplaceAllString(name, "")
+}
+
+// Fingerprints returns the fingerprint of every monitor by ID
+func Fingerprints(monitors []Monitor) map[int]Fingerprint {
+	ordered := make([]Monitor, len(monitors))
+	copy(ordered, monitors)
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].ID < ordered[j].ID
+	})
+
+	fingerprints := make(map[int]Fingerprint, len(ordered))
+	seen := make(map[string]int)
+	for _, mon := range ordered {
+		name := BaseName(mon.Name)
+		seen[name]++
+		fingerprints[mon.ID] = Fingerprint{Name: name, AppKitID: mon.AppKitID, Position: seen[name]}
+	}
+	return fingerprints
+}
+
+// Similarity scores how likely two fingerprints belong to the same monitor:
+// 3 when they are equal, 2 when only the AppKit ID agrees, 1 when only the
+// position does and 0 when the names differ or nothing else agrees
+func (f Fingerprint) Similarity(other Fingerprint) int {
+	if f == other {
+		return 3
+	}
+	if f.Name != other.Name {
+		return 0
+	}
+	score := 0
+	if f.AppKitID != 0 && f.AppKitID == other.AppKitID {
+		score += 2
+	}
+	if f.Position == other.Position {
+		score++
+	}
+	return score
+}
+
+func (f Fingerprint) String() string {
+	if f.AppKitID == 0 {
+		return fmt.Sprintf("%s, position %d", f.Name, f.Position)
+	}
+	return fmt.Sprintf("%s, screen %d, position %d", f.Name, f.AppKitID, f.Position)
+}
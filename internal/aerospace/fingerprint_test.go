@@ -0,0 +1,46 @@
+package aerospace
+
+import "testing"
+
+func TestFingerprints(t *testing.T) {
+	monitors := []Monitor{
+		{ID: 3, Name: "XZ272U P (1)", AppKitID: 3},
+		{ID: 1, Name: "XZ272U P (2)", AppKitID: 2},
+		{ID: 2, Name: "Built-in Retina Display", AppKitID: 1},
+	}
+
+	expected := map[int]Fingerprint{
+		1: {Name: "XZ272U P", AppKitID: 2, Position: 1},
+		2: {Name: "Built-in Retina Display", AppKitID: 1, Position: 1},
+		3: {Name: "XZ272U P", AppKitID: 3, Position: 2},
+	}
+	got := Fingerprints(monitors)
+	for id, fp := range expected {
+		if got[id] != fp {
+			t.Errorf("Fingerprints()[%d] = %+v, expected %+v", id, got[id], fp)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	known := Fingerprint{Name: "XZ272U P", AppKitID: 2, Position: 1}
+
+	tests := []struct {
+		name     string
+		other    Fingerprint
+		expected int
+	}{
+		{name: "equal", other: known, expected: 3},
+		{name: "moved to the right", other: Fingerprint{Name: "XZ272U P", AppKitID: 2, Position: 2}, expected: 2},
+		{name: "new screen ID", other: Fingerprint{Name: "XZ272U P", AppKitID: 3, Position: 1}, expected: 1},
+		{name: "nothing agrees", other: Fingerprint{Name: "XZ272U P", AppKitID: 3, Position: 2}},
+		{name: "different name", other: Fingerprint{Name: "DELL U2720Q", AppKitID: 2, Position: 1}},
+		{name: "equal without screen ID", other: Fingerprint{Name: "XZ272U P", Position: 1}, expected: 1},
+	}
+
+	for _, tt := range tests {
+		if got := known.Similarity(tt.other); got != tt.expected {
+			t.Errorf("%s: Similarity() = %d, expected %d", tt.name, got, tt.expected)
+		}
+	}
+}
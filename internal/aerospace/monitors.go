@@ -1,23 +1,144 @@
 package aerospace
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 // Monitor represents an Aerospace monitor with its properties
 type Monitor struct {
-	ID   int    // 1-based sequential number of the monitor
-	Name string // Name of the monitor
+	ID             int    // 1-based sequential number of the monitor
+	Name           string // Name of the monitor
+	IsMain         bool   // True for the main display in macOS settings
+	AppKitID       int    // 1-based index into NSScreen.screens, 0 when unknown
+	WorkspaceCount int    // Number of workspaces on the monitor, 0 unless listed together with workspaces
+}
+
+// monitorJSON is a single entry of list-monitors --json output
+type monitorJSON struct {
+	MonitorID   int    `json:"monitor-id"`
+	MonitorName string `json:"monitor-name"`
+	IsMain      bool   `json:"monitor-is-main"`
+	AppKitID    int    `json:"monitor-appkit-nsscreen-screens-id"`
 }
 
 // ListMonitors executes the aerospace list-monitors command and returns
-// an array of Monitor objects ordered from left to right as arranged in macOS settings
-func ListMonitors() ([]Monitor, error) {
-	cmd := exec.Command("aerospace", "list-monitors", "--format", "%{monitor-id}|%{monitor-name}")
-	output, err := cmd.Output()
+// an array of Monitor objects ordered from left to right as arranged in macOS settings.
+// JSON output is tried first, then the metadata format, then IDs and names
+// only, each time Aerospace rejects the richer query.
+func (c *Client) ListMonitors(ctx context.Context) ([]Monitor, error) {
+	var monitors []Monitor
+	var err error
+	if c.has(CapJSON) {
+		if monitors, err = c.listMonitorsJSON(ctx); rejected(err) {
+			c.lacks(CapJSON)
+		}
+	}
+	if !c.has(CapJSON) && c.has(CapMonitorMetadata) {
+		if monitors, err = c.listMonitorsLegacy(ctx); rejected(err) {
+			c.lacks(CapMonitorMetadata)
+		}
+	}
+	if !c.has(CapJSON) && !c.has(CapMonitorMetadata) {
+		if monitors, err = c.listMonitorsBasic(ctx); err != nil {
+			err = c.explain(ctx, err, CapFormat)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Order by ID, which represents left-to-right order
+	sort.Slice(monitors, func(i, j int) bool {
+		return monitors[i].ID < monitors[j].ID
+	})
+
+	return monitors, nil
+}
+
+// listMonitorsJSON lists all monitors using list-monitors --json
+func (c *Client) listMonitorsJSON(ctx context.Context) ([]Monitor, error) {
+	output, err := c.query(ctx, "list-monitors", "--json", "--format",
+		jsonFormat("monitor-id", "monitor-name", "monitor-is-main", "monitor-appkit-nsscreen-screens-id"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute aerospace list-monitors: %w", err)
+	}
+
+	var entries []monitorJSON
+	if err := json.Unmarshal(output, &entries); err != nil {
+		return nil, &ParseError{Command: "list-monitors", Line: string(output), Err: err}
+	}
+
+	monitors := make([]Monitor, 0, len(entries))
+	for _, e := range entries {
+		monitors = append(monitors, Monitor{
+			ID:       e.MonitorID,
+			Name:     e.MonitorName,
+			IsMain:   e.IsMain,
+			AppKitID: e.AppKitID,
+		})
+	}
+
+	return monitors, nil
+}
+
+// listMonitorsLegacy lists all monitors using the pipe-delimited format
+// understood by Aerospace releases without --json. The name goes last so a
+// '|' inside it can't shift the other fields.
+func (c *Client) listMonitorsLegacy(ctx context.Context) ([]Monitor, error) {
+	output, err := c.query(ctx, "list-monitors", "--format",
+		"%{monitor-id}|%{monitor-is-main}|%{monitor-appkit-nsscreen-screens-id}|%{monitor-name}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute aerospace list-monitors: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	monitors := make([]Monitor, 0, len(lines))
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, "|", 4)
+		if len(parts) != 4 {
+			return nil, &ParseError{Command: "list-monitors", Line: line, Err: fmt.Errorf("expected 4 fields, got %d", len(parts))}
+		}
+
+		id, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, &ParseError{Command: "list-monitors", Line: line, Err: fmt.Errorf("invalid monitor ID: %s", parts[0])}
+		}
+
+		isMain, err := strconv.ParseBool(parts[1])
+		if err != nil {
+			return nil, &ParseError{Command: "list-monitors", Line: line, Err: fmt.Errorf("invalid monitor-is-main value: %s", parts[1])}
+		}
+
+		appKitID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, &ParseError{Command: "list-monitors", Line: line, Err: fmt.Errorf("invalid AppKit screen ID: %s", parts[2])}
+		}
+
+		monitors = append(monitors, Monitor{
+			ID:       id,
+			Name:     parts[3],
+			IsMain:   isMain,
+			AppKitID: appKitID,
+		})
+	}
+
+	return monitors, nil
+}
+
+// listMonitorsBasic lists monitor IDs and names only, for Aerospace releases
+// that don't know about the main display or AppKit screen IDs
+func (c *Client) listMonitorsBasic(ctx context.Context) ([]Monitor, error) {
+	output, err := c.query(ctx, "list-monitors", "--format", "%{monitor-id}|%{monitor-name}")
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute aerospace list-monitors: %w", err)
 	}
@@ -32,12 +153,12 @@ func ListMonitors() ([]Monitor, error) {
 
 		parts := strings.SplitN(line, "|", 2)
 		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid monitor output format: %s", line)
+			return nil, &ParseError{Command: "list-monitors", Line: line, Err: fmt.Errorf("expected 2 fields, got %d", len(parts))}
 		}
 
 		id, err := strconv.Atoi(parts[0])
 		if err != nil {
-			return nil, fmt.Errorf("invalid monitor ID: %s", parts[0])
+			return nil, &ParseError{Command: "list-monitors", Line: line, Err: fmt.Errorf("invalid monitor ID: %s", parts[0])}
 		}
 
 		monitors = append(monitors, Monitor{
@@ -50,9 +171,43 @@ func ListMonitors() ([]Monitor, error) {
 }
 
 // GetMouseMonitorID returns the ID of the monitor that currently has the mouse cursor
-func GetMouseMonitorID() (int, error) {
-	cmd := exec.Command("aerospace", "list-monitors", "--mouse", "--format", "%{monitor-id}")
-	output, err := cmd.Output()
+func (c *Client) GetMouseMonitorID(ctx context.Context) (int, error) {
+	if c.has(CapJSON) {
+		id, err := c.mouseMonitorJSON(ctx)
+		if !rejected(err) {
+			return id, err
+		}
+		c.lacks(CapJSON)
+	}
+
+	id, err := c.mouseMonitorLegacy(ctx)
+	if err != nil {
+		return 0, c.explain(ctx, err, CapFormat, CapMouseMonitor)
+	}
+	return id, nil
+}
+
+// mouseMonitorJSON finds the mouse monitor using list-monitors --json
+func (c *Client) mouseMonitorJSON(ctx context.Context) (int, error) {
+	output, err := c.query(ctx, "list-monitors", "--mouse", "--json", "--format", jsonFormat("monitor-id"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute aerospace list-monitors --mouse: %w", err)
+	}
+
+	var entries []monitorJSON
+	if err := json.Unmarshal(output, &entries); err != nil {
+		return 0, &ParseError{Command: "list-monitors", Line: string(output), Err: err}
+	}
+	if len(entries) != 1 {
+		return 0, &ParseError{Command: "list-monitors", Line: string(output), Err: fmt.Errorf("expected exactly one mouse monitor, got %d", len(entries))}
+	}
+
+	return entries[0].MonitorID, nil
+}
+
+// mouseMonitorLegacy finds the mouse monitor for Aerospace releases without --json
+func (c *Client) mouseMonitorLegacy(ctx context.Context) (int, error) {
+	output, err := c.query(ctx, "list-monitors", "--mouse", "--format", "%{monitor-id}")
 	if err != nil {
 		return 0, fmt.Errorf("failed to execute aerospace list-monitors --mouse: %w", err)
 	}
@@ -60,7 +215,7 @@ func GetMouseMonitorID() (int, error) {
 	idStr := strings.TrimSpace(string(output))
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return 0, fmt.Errorf("invalid monitor ID: %s", idStr)
+		return 0, &ParseError{Command: "list-monitors", Line: idStr, Err: fmt.Errorf("invalid monitor ID: %s", idStr)}
 	}
 
 	return id, nil
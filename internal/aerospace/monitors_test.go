@@ -4,16 +4,27 @@ import (
 	"testing"
 )
 
+const listMonitorsQuery = "list-monitors --format %{monitor-id}|%{monitor-is-main}|%{monitor-appkit-nsscreen-screens-id}|%{monitor-name}"
+
+const listMonitorsOutput = `1|false|2|XZ272U P (2)
+2|true|1|Built-in Retina Display
+3|false|3|XZ272U P (1)
+`
+
 func TestListMonitors(t *testing.T) {
-	monitors, err := ListMonitors()
+	client := NewClient(&cannedRunner{outputs: map[string]string{
+		listMonitorsQuery: listMonitorsOutput,
+	}})
+
+	monitors, err := client.ListMonitors(t.Context())
 	if err != nil {
 		t.Fatalf("ListMonitors() error = %v", err)
 	}
 
 	expected := []Monitor{
-		{ID: 1, Name: "XZ272U P (2)"},
-		{ID: 2, Name: "Built-in Retina Display"},
-		{ID: 3, Name: "XZ272U P (1)"},
+		{ID: 1, Name: "XZ272U P (2)", AppKitID: 2},
+		{ID: 2, Name: "Built-in Retina Display", IsMain: true, AppKitID: 1},
+		{ID: 3, Name: "XZ272U P (1)", AppKitID: 3},
 	}
 
 	if len(monitors) != len(expected) {
@@ -24,17 +35,34 @@ func TestListMonitors(t *testing.T) {
 		if i >= len(expected) {
 			break
 		}
-		if monitor.ID != expected[i].ID {
-			t.Errorf("Monitor[%d].ID = %d, expected %d", i, monitor.ID, expected[i].ID)
-		}
-		if monitor.Name != expected[i].Name {
-			t.Errorf("Monitor[%d].Name = %q, expected %q", i, monitor.Name, expected[i].Name)
+		if monitor != expected[i] {
+			t.Errorf("Monitor[%d] = %+v, expected %+v", i, monitor, expected[i])
 		}
 	}
 }
 
+func TestListMonitorsWithoutMetadata(t *testing.T) {
+	client := NewClient(&cannedRunner{outputs: map[string]string{
+		"list-monitors --format %{monitor-id}|%{monitor-name}": "2|Built-in Retina Display\n1|XZ272U P (2)\n",
+	}})
+
+	monitors, err := client.ListMonitors(t.Context())
+	if err != nil {
+		t.Fatalf("ListMonitors() error = %v", err)
+	}
+
+	expected := []Monitor{{ID: 1, Name: "XZ272U P (2)"}, {ID: 2, Name: "Built-in Retina Display"}}
+	if len(monitors) != len(expected) || monitors[0] != expected[0] || monitors[1] != expected[1] {
+		t.Errorf("ListMonitors() = %+v, expected %+v", monitors, expected)
+	}
+}
+
 func TestGetMouseMonitorID(t *testing.T) {
-	id, err := GetMouseMonitorID()
+	client := NewClient(&cannedRunner{outputs: map[string]string{
+		"list-monitors --mouse --format %{monitor-id}": "1\n",
+	}})
+
+	id, err := client.GetMouseMonitorID(t.Context())
 	if err != nil {
 		t.Fatalf("GetMouseMonitorID() error = %v", err)
 	}
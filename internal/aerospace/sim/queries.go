@@ -0,0 +1,240 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+)
+
+// jsonVersion is the first simulated release whose list commands accept --json
+var jsonVersion = aerospace.Version{Major: 0, Minor: 15, Patch: 0}
+
+var variablePattern = regexp.MustCompile(`%\{([a-z-]+)\}`)
+
+// record maps format variables to their values for a single output row
+type record map[string]any
+
+// listOptions are the flags shared by the list commands
+type listOptions struct {
+	json   bool
+	format string
+	flags  map[string]string // Remaining flags, with "" for flags without a value
+}
+
+// parseListArgs splits list command arguments into options. valued names the
+// flags that take a value.
+func parseListArgs(args []string, valued ...string) (listOptions, error) {
+	opts := listOptions{flags: make(map[string]string)}
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--json":
+			opts.json = true
+		case arg == "--format":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--format requires a value")
+			}
+			opts.format = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--"):
+			value := ""
+			for _, v := range valued {
+				if v == arg {
+					if i+1 >= len(args) {
+						return opts, fmt.Errorf("%s requires a value", arg)
+					}
+					value = args[i+1]
+					i++
+				}
+			}
+			opts.flags[arg] = value
+		default:
+			return opts, fmt.Errorf("unexpected argument '%s'", arg)
+		}
+	}
+	return opts, nil
+}
+
+func (s *Server) listWorkspaces(args []string) ([]byte, []byte, error) {
+	opts, err := parseListArgs(args, "--monitor")
+	if err != nil {
+		return s.fail(2, "ERROR: "+err.Error())
+	}
+
+	monitorFilter := 0
+	if value, ok := opts.flags["--monitor"]; ok {
+		if monitorFilter, err = s.resolveMonitor(value); err != nil {
+			return s.fail(2, "ERROR: "+err.Error())
+		}
+	}
+	_, onlyFocused := opts.flags["--focused"]
+	_, onlyVisible := opts.flags["--visible"]
+
+	var records []record
+	for _, ws := range s.workspaces {
+		if monitorFilter != 0 && ws.monitor != monitorFilter {
+			continue
+		}
+		if onlyFocused && ws.name != s.focused {
+			continue
+		}
+		if onlyVisible && !s.isVisible(ws.name) {
+			continue
+		}
+		records = append(records, s.workspaceRecord(ws))
+	}
+
+	return s.render(records, opts, "%{workspace}")
+}
+
+func (s *Server) listMonitors(args []string) ([]byte, []byte, error) {
+	opts, err := parseListArgs(args)
+	if err != nil {
+		return s.fail(2, "ERROR: "+err.Error())
+	}
+	_, onlyMouse := opts.flags["--mouse"]
+	_, onlyFocused := opts.flags["--focused"]
+
+	var records []record
+	for i := range s.monitors {
+		id := i + 1
+		if onlyMouse && id != s.mouse {
+			continue
+		}
+		if onlyFocused && id != s.focusedMonitor() {
+			continue
+		}
+		records = append(records, s.monitorRecord(id))
+	}
+
+	return s.render(records, opts, "%{monitor-id} | %{monitor-name}")
+}
+
+func (s *Server) listWindows(args []string) ([]byte, []byte, error) {
+	opts, err := parseListArgs(args, "--workspace", "--monitor")
+	if err != nil {
+		return s.fail(2, "ERROR: "+err.Error())
+	}
+
+	monitorFilter := 0
+	if value, ok := opts.flags["--monitor"]; ok {
+		if monitorFilter, err = s.resolveMonitor(value); err != nil {
+			return s.fail(2, "ERROR: "+err.Error())
+		}
+	}
+	workspaceFilter, byWorkspace := opts.flags["--workspace"]
+	_, onlyFocused := opts.flags["--focused"]
+	_, all := opts.flags["--all"]
+	if !all && !onlyFocused && !byWorkspace && monitorFilter == 0 {
+		return s.fail(2, "ERROR: Mandatory option is not specified (--all|--focused|--monitor|--workspace)")
+	}
+
+	var records []record
+	for _, w := range s.windows {
+		if onlyFocused && w.id != s.focusedWindow {
+			continue
+		}
+		if byWorkspace && w.workspace != workspaceFilter {
+			continue
+		}
+		if monitorFilter != 0 && s.workspace(w.workspace).monitor != monitorFilter {
+			continue
+		}
+		records = append(records, s.windowRecord(w))
+	}
+
+	return s.render(records, opts, "%{window-id} | %{app-name} | %{window-title}")
+}
+
+// resolveMonitor turns a --monitor value into a monitor ID
+func (s *Server) resolveMonitor(value string) (int, error) {
+	switch value {
+	case "mouse":
+		return s.mouse, nil
+	case "focused":
+		return s.focusedMonitor(), nil
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || s.monitor(id) == nil {
+		return 0, fmt.Errorf("Can't find monitor '%s'", value)
+	}
+	return id, nil
+}
+
+func (s *Server) monitorRecord(id int) record {
+	m := s.monitor(id)
+	return record{
+		"monitor-id":                         id,
+		"monitor-name":                       m.name,
+		"monitor-is-main":                    m.isMain,
+		"monitor-appkit-nsscreen-screens-id": m.appKitID,
+	}
+}
+
+func (s *Server) workspaceRecord(ws *workspace) record {
+	r := s.monitorRecord(ws.monitor)
+	r["workspace"] = ws.name
+	r["workspace-is-focused"] = ws.name == s.focused
+	r["workspace-is-visible"] = s.isVisible(ws.name)
+	return r
+}
+
+func (s *Server) windowRecord(w *window) record {
+	r := s.workspaceRecord(s.workspace(w.workspace))
+	r["window-id"] = w.id
+	r["window-title"] = w.title
+	r["app-name"] = w.appName
+	r["app-bundle-id"] = w.bundleID
+	return r
+}
+
+// render prints records using the format, or as JSON objects holding the
+// variables named in the format
+func (s *Server) render(records []record, opts listOptions, defaultFormat string) ([]byte, []byte, error) {
+	format := opts.format
+	if format == "" {
+		format = defaultFormat
+	}
+
+	variables := variablePattern.FindAllStringSubmatch(format, -1)
+	for _, v := range variables {
+		if len(records) > 0 {
+			if _, ok := records[0][v[1]]; !ok {
+				return s.fail(2, fmt.Sprintf("ERROR: Unknown interpolation variable '%s'", v[1]))
+			}
+		}
+	}
+
+	if opts.json {
+		version, err := aerospace.ParseVersion(s.version)
+		if err != nil || !version.AtLeast(jsonVersion) {
+			return s.fail(2, "ERROR: Unknown flag '--json'")
+		}
+
+		objects := make([]record, 0, len(records))
+		for _, r := range records {
+			object := record{}
+			for _, v := range variables {
+				object[v[1]] = r[v[1]]
+			}
+			objects = append(objects, object)
+		}
+		output, err := json.MarshalIndent(objects, "", "    ")
+		if err != nil {
+			return s.fail(1, "ERROR: "+err.Error())
+		}
+		return append(output, '\n'), nil, nil
+	}
+
+	var sb strings.Builder
+	for _, r := range records {
+		sb.WriteString(variablePattern.ReplaceAllStringFunc(format, func(match string) string {
+			return fmt.Sprint(r[match[2:len(match)-1]])
+		}))
+		sb.WriteString("\n")
+	}
+	return []byte(sb.String()), nil, nil
+}
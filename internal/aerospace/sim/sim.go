@@ -0,0 +1,474 @@
+// Package sim is an in-memory stand-in for the Aerospace server. A Server
+// implements aerospace.Runner, so a Client backed by it runs the same queries
+// and commands it would against a real Aerospace, and tests can assert on the
+// resulting monitors, workspaces, windows and focus.
+package sim
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultVersion is the Aerospace release a Server reports unless told otherwise
+const DefaultVersion = "0.18.5-Beta sim"
+
+// Names returns the workspace names prefix1 to prefixN, like the roles of
+// the default config use
+func Names(prefix string, n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = prefix + strconv.Itoa(i+1)
+	}
+	return names
+}
+
+// Monitor describes a monitor to connect when creating a Server
+type Monitor struct {
+	Name       string   // Name of the monitor
+	IsMain     bool     // True for the main display, defaults to the first monitor
+	AppKitID   int      // Index into NSScreen.screens, assigned main-first when 0
+	Workspaces []string // Workspaces initially on the monitor, the first one is visible
+}
+
+type monitor struct {
+	name     string
+	isMain   bool
+	appKitID int
+	visible  string // Name of the workspace shown on the monitor
+}
+
+type workspace struct {
+	name       string
+	monitor    int // 1-based monitor ID
+	persistent bool
+}
+
+type window struct {
+	id        int
+	appName   string
+	bundleID  string
+	title     string
+	workspace string
+}
+
+// Server simulates a running Aerospace server
+type Server struct {
+	mu            sync.Mutex
+	version       string
+	monitors      []*monitor // Ordered left to right, so the index is the ID minus one
+	workspaces    []*workspace
+	windows       []*window
+	focused       string // Name of the focused workspace
+	focusedWindow int    // ID of the focused window, 0 if none
+	mouse         int    // ID of the monitor under the mouse
+	nextWindowID  int
+	log           []string
+}
+
+// New creates a Server with the given monitors ordered from left to right.
+// Every initial workspace is persistent, the visible workspace of the main
+// monitor is focused and the mouse rests on the main monitor.
+func New(monitors ...Monitor) *Server {
+	s := &Server{version: DefaultVersion, nextWindowID: 1000}
+
+	hasMain := slices.ContainsFunc(monitors, func(m Monitor) bool { return m.IsMain })
+	for i, spec := range monitors {
+		m := &monitor{name: spec.Name, isMain: spec.IsMain || (!hasMain && i == 0), appKitID: spec.AppKitID}
+		s.monitors = append(s.monitors, m)
+		for _, name := range spec.Workspaces {
+			s.workspaces = append(s.workspaces, &workspace{name: name, monitor: i + 1, persistent: true})
+		}
+	}
+
+	// Assign AppKit IDs main-first, the way NSScreen.screens orders them
+	next := 1
+	for _, m := range s.monitors {
+		if m.isMain && m.appKitID == 0 {
+			m.appKitID = next
+			next++
+		}
+	}
+	for _, m := range s.monitors {
+		if m.appKitID == 0 {
+			m.appKitID = next
+			next++
+		}
+	}
+
+	for id, m := range s.monitors {
+		if m.visible = s.firstWorkspaceOn(id + 1); m.visible == "" {
+			m.visible = s.createWorkspace(id+1, "")
+		}
+		if m.isMain {
+			s.mouse = id + 1
+			s.focused = m.visible
+		}
+	}
+
+	return s
+}
+
+// SetVersion changes the Aerospace release the server reports
+func (s *Server) SetVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version = version
+}
+
+// SetMouse moves the mouse cursor onto the monitor with the given ID
+func (s *Server) SetMouse(monitorID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mouse = monitorID
+}
+
+// AddWindow opens a window of the given app on a workspace and returns its ID.
+// The window takes focus if its workspace is focused.
+func (s *Server) AddWindow(appName, workspaceName string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.workspace(workspaceName) == nil {
+		s.createWorkspace(s.focusedMonitor(), workspaceName)
+	}
+
+	s.nextWindowID++
+	w := &window{
+		id:        s.nextWindowID,
+		appName:   appName,
+		bundleID:  "com.example." + strings.ToLower(strings.ReplaceAll(appName, " ", "")),
+		title:     appName + " window",
+		workspace: workspaceName,
+	}
+	s.windows = append(s.windows, w)
+	if workspaceName == s.focused {
+		s.focusedWindow = w.id
+	}
+
+	return w.id
+}
+
+// Focus switches to a workspace without recording it in the command log
+func (s *Server) Focus(workspaceName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.focusWorkspace(workspaceName)
+}
+
+// Log returns the state-changing commands run so far, space-joined
+func (s *Server) Log() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.log)
+}
+
+// FocusedWorkspace returns the name of the focused workspace
+func (s *Server) FocusedWorkspace() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.focused
+}
+
+// VisibleOn returns the name of the workspace shown on a monitor
+func (s *Server) VisibleOn(monitorID int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m := s.monitor(monitorID); m != nil {
+		return m.visible
+	}
+	return ""
+}
+
+// MonitorOf returns the ID of the monitor a workspace is on, or 0 if it doesn't exist
+func (s *Server) MonitorOf(workspaceName string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ws := s.workspace(workspaceName); ws != nil {
+		return ws.monitor
+	}
+	return 0
+}
+
+// WorkspacesOn returns the names of the workspaces on a monitor
+func (s *Server) WorkspacesOn(monitorID int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var names []string
+	for _, ws := range s.workspaces {
+		if ws.monitor == monitorID {
+			names = append(names, ws.name)
+		}
+	}
+	return names
+}
+
+// WindowWorkspace returns the workspace a window is on, or "" if it doesn't exist
+func (s *Server) WindowWorkspace(windowID int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.windows {
+		if w.id == windowID {
+			return w.workspace
+		}
+	}
+	return ""
+}
+
+// Run interprets an aerospace CLI invocation against the simulated state
+func (s *Server) Run(ctx context.Context, args ...string) ([]byte, []byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(args) == 0 {
+		return s.fail(2, "ERROR: no command given")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slices.Contains(args[1:], "--help") {
+		return s.help(args[0])
+	}
+	switch args[0] {
+	case "--version":
+		return []byte(fmt.Sprintf("aerospace CLI client version: %s\nAeroSpace.app server version: %s\n", s.version, s.version)), nil, nil
+	case "list-workspaces":
+		return s.listWorkspaces(args[1:])
+	case "list-monitors":
+		return s.listMonitors(args[1:])
+	case "list-windows":
+		return s.listWindows(args[1:])
+	}
+
+	s.log = append(s.log, strings.Join(args, " "))
+	switch args[0] {
+	case "workspace":
+		return s.cmdWorkspace(args[1:])
+	case "move-workspace-to-monitor":
+		return s.cmdMoveWorkspaceToMonitor(args[1:])
+	case "move-node-to-workspace":
+		return s.cmdMoveNodeToWorkspace(args[1:])
+	default:
+		return s.fail(2, fmt.Sprintf("ERROR: Unknown command '%s'", args[0]))
+	}
+}
+
+// usages is the --help output of the commands the server understands
+var usages = map[string]string{
+	"list-workspaces":           "USAGE: list-workspaces [-h|--help] (--all|--focused|--monitor <monitor-id>...) [--format <output-format>] [--json]",
+	"list-monitors":             "USAGE: list-monitors [-h|--help] [--focused] [--mouse] [--format <output-format>] [--json]",
+	"list-windows":              "USAGE: list-windows [-h|--help] (--all|--focused|--monitor <monitor-id>|--workspace <workspace>) [--format <output-format>] [--json]",
+	"workspace":                 "USAGE: workspace [-h|--help] <workspace-name>",
+	"move-workspace-to-monitor": "USAGE: move-workspace-to-monitor [-h|--help] [--workspace <workspace>] <monitor-id>",
+	"move-node-to-workspace":    "USAGE: move-node-to-workspace [-h|--help] [--focus-follows-window] <workspace-name>",
+}
+
+// help prints the usage of a command without running it
+func (s *Server) help(command string) ([]byte, []byte, error) {
+	usage, ok := usages[command]
+	if !ok {
+		return s.fail(2, fmt.Sprintf("ERROR: Unknown command '%s'", command))
+	}
+	return []byte(usage + "\n"), nil, nil
+}
+
+// fail reports a command that exited with the given code
+func (s *Server) fail(code int, message string) ([]byte, []byte, error) {
+	return nil, []byte(message + "\n"), fmt.Errorf("exit status %d", code)
+}
+
+func (s *Server) cmdWorkspace(args []string) ([]byte, []byte, error) {
+	if len(args) != 1 {
+		return s.fail(2, "ERROR: workspace requires exactly one workspace name")
+	}
+	s.focusWorkspace(args[0])
+	return nil, nil, nil
+}
+
+func (s *Server) cmdMoveWorkspaceToMonitor(args []string) ([]byte, []byte, error) {
+	var name, target string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--workspace" && i+1 < len(args) {
+			name = args[i+1]
+			i++
+		} else {
+			target = args[i]
+		}
+	}
+	if name == "" {
+		name = s.focused
+	}
+
+	ws := s.workspace(name)
+	if ws == nil {
+		return s.fail(2, fmt.Sprintf("ERROR: Workspace '%s' doesn't exist", name))
+	}
+	targetID, err := strconv.Atoi(target)
+	if err != nil || s.monitor(targetID) == nil {
+		return s.fail(2, fmt.Sprintf("ERROR: Can't find monitor '%s'", target))
+	}
+	if ws.monitor == targetID {
+		return []byte(fmt.Sprintf("Workspace '%s' is already on the target monitor\n", name)), nil, nil
+	}
+
+	// A visible workspace stays visible on its new monitor and the old
+	// monitor shows another of its workspaces, or a fresh one
+	source := s.monitor(ws.monitor)
+	wasVisible := source.visible == name
+	ws.monitor = targetID
+	if wasVisible {
+		if source.visible = s.firstWorkspaceOn(s.monitorID(source)); source.visible == "" {
+			source.visible = s.createWorkspace(s.monitorID(source), "")
+		}
+		s.show(targetID, name)
+	}
+	s.collectGarbage()
+
+	return nil, nil, nil
+}
+
+func (s *Server) cmdMoveNodeToWorkspace(args []string) ([]byte, []byte, error) {
+	follow := false
+	var name string
+	for _, arg := range args {
+		if arg == "--focus-follows-window" {
+			follow = true
+		} else {
+			name = arg
+		}
+	}
+	if name == "" {
+		return s.fail(2, "ERROR: move-node-to-workspace requires a workspace name")
+	}
+
+	w := s.window(s.focusedWindow)
+	if w == nil {
+		return s.fail(1, "No window is focused")
+	}
+	if s.workspace(name) == nil {
+		s.createWorkspace(s.focusedMonitor(), name)
+	}
+
+	w.workspace = name
+	if follow {
+		s.focusWorkspace(name)
+		s.focusedWindow = w.id
+	} else {
+		s.focusedWindow = s.firstWindowOn(s.focused)
+	}
+	s.collectGarbage()
+
+	return nil, nil, nil
+}
+
+// focusWorkspace shows and focuses a workspace, creating it on the focused
+// monitor if it doesn't exist
+func (s *Server) focusWorkspace(name string) {
+	ws := s.workspace(name)
+	if ws == nil {
+		s.createWorkspace(s.focusedMonitor(), name)
+		ws = s.workspace(name)
+	}
+
+	s.show(ws.monitor, name)
+	s.focused = name
+	s.focusedWindow = s.firstWindowOn(name)
+	s.collectGarbage()
+}
+
+// show makes a workspace the visible one on a monitor
+func (s *Server) show(monitorID int, name string) {
+	s.monitor(monitorID).visible = name
+}
+
+// createWorkspace adds a non-persistent workspace to a monitor. An empty name
+// picks the lowest free number, like Aerospace does for monitors left empty.
+func (s *Server) createWorkspace(monitorID int, name string) string {
+	if name == "" {
+		for n := 1; ; n++ {
+			if s.workspace(strconv.Itoa(n)) == nil {
+				name = strconv.Itoa(n)
+				break
+			}
+		}
+	}
+	s.workspaces = append(s.workspaces, &workspace{name: name, monitor: monitorID})
+	return name
+}
+
+// collectGarbage removes empty, hidden workspaces that aren't persistent
+func (s *Server) collectGarbage() {
+	s.workspaces = slices.DeleteFunc(s.workspaces, func(ws *workspace) bool {
+		return !ws.persistent && !s.isVisible(ws.name) && s.firstWindowOn(ws.name) == 0
+	})
+}
+
+func (s *Server) isVisible(name string) bool {
+	for _, m := range s.monitors {
+		if m.visible == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) workspace(name string) *workspace {
+	for _, ws := range s.workspaces {
+		if ws.name == name {
+			return ws
+		}
+	}
+	return nil
+}
+
+func (s *Server) monitor(id int) *monitor {
+	if id < 1 || id > len(s.monitors) {
+		return nil
+	}
+	return s.monitors[id-1]
+}
+
+func (s *Server) monitorID(m *monitor) int {
+	return slices.Index(s.monitors, m) + 1
+}
+
+func (s *Server) window(id int) *window {
+	for _, w := range s.windows {
+		if w.id == id {
+			return w
+		}
+	}
+	return nil
+}
+
+// focusedMonitor returns the ID of the monitor showing the focused workspace
+func (s *Server) focusedMonitor() int {
+	if ws := s.workspace(s.focused); ws != nil {
+		return ws.monitor
+	}
+	return 1
+}
+
+// firstWorkspaceOn returns the first workspace on a monitor, or "" if it has none
+func (s *Server) firstWorkspaceOn(monitorID int) string {
+	for _, ws := range s.workspaces {
+		if ws.monitor == monitorID {
+			return ws.name
+		}
+	}
+	return ""
+}
+
+func (s *Server) firstWindowOn(workspaceName string) int {
+	for _, w := range s.windows {
+		if w.workspace == workspaceName {
+			return w.id
+		}
+	}
+	return 0
+}
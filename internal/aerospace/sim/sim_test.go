@@ -0,0 +1,125 @@
+package sim
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+)
+
+// threeMonitors builds the author's desk: two identical externals around the built-in display
+func threeMonitors() *Server {
+	return New(
+		Monitor{Name: "XZ272U P (2)", Workspaces: []string{"L1", "L2", "L3", "L4", "L5"}},
+		Monitor{Name: "Built-in Retina Display", IsMain: true, Workspaces: []string{"B1", "B2", "B3", "B4", "B5"}},
+		Monitor{Name: "XZ272U P (1)", Workspaces: []string{"R1", "R2", "R3", "R4", "R5"}},
+	)
+}
+
+func TestClientQueries(t *testing.T) {
+	for _, version := range []string{DefaultVersion, "0.14.2-Beta sim"} {
+		server := threeMonitors()
+		server.SetVersion(version)
+		server.SetMouse(3)
+		server.AddWindow("Ghostty", "B1")
+		client := aerospace.NewClient(server)
+
+		state, err := client.Snapshot(t.Context(), aerospace.SnapshotOptions{Windows: true})
+		if err != nil {
+			t.Fatalf("Snapshot() on %s error = %v", version, err)
+		}
+
+		expectedMonitors := []aerospace.Monitor{
+			{ID: 1, Name: "XZ272U P (2)", AppKitID: 2, WorkspaceCount: 5},
+			{ID: 2, Name: "Built-in Retina Display", IsMain: true, AppKitID: 1, WorkspaceCount: 5},
+			{ID: 3, Name: "XZ272U P (1)", AppKitID: 3, WorkspaceCount: 5},
+		}
+		if !reflect.DeepEqual(state.Monitors, expectedMonitors) {
+			t.Errorf("Monitors on %s = %+v, expected %+v", version, state.Monitors, expectedMonitors)
+		}
+		if state.FocusedWorkspace != "B1" || state.MouseMonitorID != 3 {
+			t.Errorf("Focused %q with mouse on %d on %s, expected B1 with mouse on 3", state.FocusedWorkspace, state.MouseMonitorID, version)
+		}
+		if len(state.Workspaces) != 15 || state.VisibleWorkspaceOn(1) != "L1" {
+			t.Errorf("Got %d workspaces with %q visible on monitor 1 on %s, expected 15 with L1", len(state.Workspaces), state.VisibleWorkspaceOn(1), version)
+		}
+		if len(state.Windows) != 1 || state.Windows[0].AppName != "Ghostty" || state.Windows[0].MonitorID != 2 {
+			t.Errorf("Windows on %s = %+v, expected one Ghostty window on monitor 2", version, state.Windows)
+		}
+	}
+}
+
+func TestMoveVisibleWorkspaceToMonitor(t *testing.T) {
+	server := threeMonitors()
+	client := aerospace.NewClient(server)
+
+	if err := client.MoveWorkspaceToMonitor(t.Context(), "L1", 3); err != nil {
+		t.Fatalf("MoveWorkspaceToMonitor() error = %v", err)
+	}
+
+	if server.MonitorOf("L1") != 3 {
+		t.Errorf("L1 is on monitor %d, expected 3", server.MonitorOf("L1"))
+	}
+	if server.VisibleOn(3) != "L1" || server.VisibleOn(1) != "L2" {
+		t.Errorf("Visible workspaces are %q and %q, expected L1 on monitor 3 and L2 on monitor 1", server.VisibleOn(3), server.VisibleOn(1))
+	}
+
+	// Moving it again succeeds with a warning
+	if err := client.MoveWorkspaceToMonitor(t.Context(), "L1", 3); err != nil {
+		t.Errorf("MoveWorkspaceToMonitor() to its own monitor error = %v, expected success", err)
+	}
+	if len(client.Warnings()) != 1 {
+		t.Errorf("Warnings() = %v, expected one", client.Warnings())
+	}
+}
+
+func TestMoveLastWorkspaceLeavesFreshOne(t *testing.T) {
+	server := New(
+		Monitor{Name: "Built-in Retina Display", Workspaces: []string{"B1"}},
+		Monitor{Name: "DELL U2720Q", Workspaces: []string{"L1"}},
+	)
+	client := aerospace.NewClient(server)
+
+	if err := client.MoveWorkspaceToMonitor(t.Context(), "L1", 1); err != nil {
+		t.Fatalf("MoveWorkspaceToMonitor() error = %v", err)
+	}
+	if visible := server.VisibleOn(2); visible != "1" {
+		t.Errorf("Monitor 2 shows %q, expected a fresh workspace named 1", visible)
+	}
+
+	// The fresh workspace is dropped once it is no longer shown
+	if err := client.MoveWorkspaceToMonitor(t.Context(), "L1", 2); err != nil {
+		t.Fatalf("MoveWorkspaceToMonitor() error = %v", err)
+	}
+	if !reflect.DeepEqual(server.WorkspacesOn(2), []string{"L1"}) {
+		t.Errorf("Monitor 2 has workspaces %v, expected only L1", server.WorkspacesOn(2))
+	}
+}
+
+func TestMoveNodeToWorkspace(t *testing.T) {
+	server := threeMonitors()
+	id := server.AddWindow("Safari", "B1")
+	client := aerospace.NewClient(server)
+
+	if err := client.MoveNodeToWorkspace(t.Context(), "R2", false); err != nil {
+		t.Fatalf("MoveNodeToWorkspace() error = %v", err)
+	}
+	if server.WindowWorkspace(id) != "R2" || server.FocusedWorkspace() != "B1" {
+		t.Errorf("Window on %q with %q focused, expected R2 with B1 still focused", server.WindowWorkspace(id), server.FocusedWorkspace())
+	}
+
+	// Nothing is focused any more
+	if err := client.MoveNodeToWorkspace(t.Context(), "R3", false); err == nil {
+		t.Errorf("MoveNodeToWorkspace() without a focused window error = nil, expected an error")
+	}
+}
+
+func TestUnknownMonitor(t *testing.T) {
+	client := aerospace.NewClient(threeMonitors())
+
+	err := client.MoveWorkspaceToMonitor(t.Context(), "B1", 7)
+	if !errors.Is(err, aerospace.ErrUnknownMonitor) {
+		t.Errorf("MoveWorkspaceToMonitor() error = %v, expected ErrUnknownMonitor", err)
+	}
+}
@@ -0,0 +1,151 @@
+package aerospace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+)
+
+// socketRequest is the JSON message the aerospace CLI sends to the server
+type socketRequest struct {
+	Args  []string `json:"args"`
+	Stdin string   `json:"stdin"`
+}
+
+// socketAnswer is the JSON message the server replies with
+type socketAnswer struct {
+	ExitCode             int32  `json:"exitCode"`
+	Stdout               string `json:"stdout"`
+	Stderr               string `json:"stderr"`
+	ServerVersionAndHash string `json:"serverVersionAndHash"`
+}
+
+// SocketRunner runs commands by talking to the Aerospace server over its Unix
+// socket, reusing a single connection for every call
+type SocketRunner struct {
+	mu            sync.Mutex
+	conn          net.Conn
+	enc           *json.Encoder
+	dec           *json.Decoder
+	serverVersion string // Version reported in the most recent answer
+	broken        error  // Set once a round trip was interrupted and the stream can't be trusted
+}
+
+// DefaultSocketPath returns the socket the Aerospace server listens on for the current user
+func DefaultSocketPath() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	return fmt.Sprintf("/tmp/bobko.aerospace-%s.sock", name)
+}
+
+// DialSocket connects to the Aerospace server listening on the given socket path
+func DialSocket(ctx context.Context, path string) (*SocketRunner, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to connect to socket %s: %v", ErrServerNotRunning, path, err)
+	}
+
+	return &SocketRunner{
+		conn: conn,
+		enc:  json.NewEncoder(conn),
+		dec:  json.NewDecoder(conn),
+	}, nil
+}
+
+// Run sends a single request over the connection and waits for its answer
+// until ctx is done
+func (r *SocketRunner) Run(ctx context.Context, args ...string) ([]byte, []byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.send(ctx, args)
+}
+
+// send performs a request/answer round trip; the caller must hold r.mu
+func (r *SocketRunner) send(ctx context.Context, args []string) ([]byte, []byte, error) {
+	if r.broken != nil {
+		return nil, nil, fmt.Errorf("aerospace socket is unusable after an earlier failure: %w", r.broken)
+	}
+	if args == nil {
+		args = []string{}
+	}
+
+	// Interrupt blocked reads and writes as soon as ctx is done
+	deadline, _ := ctx.Deadline()
+	r.conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() {
+		r.conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := r.enc.Encode(socketRequest{Args: args}); err != nil {
+		return nil, nil, r.fail(ctx, fmt.Errorf("failed to send request to aerospace socket: %w", err))
+	}
+
+	var answer socketAnswer
+	if err := r.dec.Decode(&answer); err != nil {
+		return nil, nil, r.fail(ctx, fmt.Errorf("failed to read answer from aerospace socket: %w", err))
+	}
+
+	if answer.ServerVersionAndHash != "" {
+		r.serverVersion = answer.ServerVersionAndHash
+	}
+
+	stdout, stderr := []byte(answer.Stdout), []byte(answer.Stderr)
+	if answer.ExitCode != 0 {
+		return stdout, stderr, fmt.Errorf("aerospace exited with code %d", answer.ExitCode)
+	}
+
+	return stdout, stderr, nil
+}
+
+// fail marks the connection as unusable, since a late answer to an interrupted
+// request would be mistaken for the answer to the next one
+func (r *SocketRunner) fail(ctx context.Context, err error) error {
+	r.broken = err
+	r.conn.Close()
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w: %v", ctx.Err(), err)
+	}
+	return err
+}
+
+// ServerVersion returns the version the server reports in its answers,
+// sending a harmless query if no answer has been received yet
+func (r *SocketRunner) ServerVersion(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.serverVersion == "" {
+		if _, _, err := r.send(ctx, []string{"list-monitors"}); err != nil && r.serverVersion == "" {
+			return "", err
+		}
+	}
+	if r.serverVersion == "" {
+		return "", fmt.Errorf("aerospace server did not report its version")
+	}
+	return r.serverVersion, nil
+}
+
+// Close closes the connection to the server
+func (r *SocketRunner) Close() error {
+	return r.conn.Close()
+}
+
+// NewSocketClient creates a Client that talks to the Aerospace server over
+// the given Unix socket. The client must be closed when no longer needed.
+func NewSocketClient(ctx context.Context, path string) (*Client, error) {
+	runner, err := DialSocket(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(runner), nil
+}
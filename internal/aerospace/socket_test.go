@@ -0,0 +1,165 @@
+package aerospace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeServer is a stand-in for the Aerospace server that answers socket
+// requests from a table keyed by the space-joined arguments
+type fakeServer struct {
+	path        string
+	outputs     map[string]string
+	connections atomic.Int32
+}
+
+func startFakeServer(t *testing.T, outputs map[string]string) *fakeServer {
+	t.Helper()
+
+	// Unix socket paths are limited in length, so avoid the long t.TempDir()
+	dir, err := os.MkdirTemp("", "aero")
+	if err != nil {
+		t.Fatalf("failed to create socket directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	server := &fakeServer{path: filepath.Join(dir, "server.sock"), outputs: outputs}
+	listener, err := net.Listen("unix", server.path)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", server.path, err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.connections.Add(1)
+			go server.serve(conn)
+		}
+	}()
+
+	return server
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+
+	for {
+		var request socketRequest
+		if err := dec.Decode(&request); err != nil {
+			return
+		}
+
+		// A hung server reads the request but never answers
+		if len(request.Args) > 0 && request.Args[0] == "hang" {
+			continue
+		}
+
+		answer := socketAnswer{ServerVersionAndHash: "0.14.2-Beta 3a8d6a1"}
+		if out, ok := s.outputs[strings.Join(request.Args, " ")]; ok {
+			answer.Stdout = out
+		} else {
+			answer.ExitCode = 2
+			answer.Stderr = "Unknown command: " + strings.Join(request.Args, " ")
+		}
+		if err := enc.Encode(answer); err != nil {
+			return
+		}
+	}
+}
+
+func TestSocketClientReusesConnection(t *testing.T) {
+	server := startFakeServer(t, map[string]string{
+		listWorkspacesQuery: listWorkspacesOutput,
+		listMonitorsQuery:   listMonitorsOutput,
+		"list-monitors --mouse --format %{monitor-id}": "3\n",
+		"workspace R2": "",
+	})
+
+	client, err := NewSocketClient(t.Context(), server.path)
+	if err != nil {
+		t.Fatalf("NewSocketClient() error = %v", err)
+	}
+	defer client.Close()
+
+	workspaces, monitors, err := client.ListWorkspacesAndMonitors(t.Context())
+	if err != nil {
+		t.Fatalf("ListWorkspacesAndMonitors() error = %v", err)
+	}
+	if len(workspaces) != 15 || len(monitors) != 3 {
+		t.Errorf("ListWorkspacesAndMonitors() returned %d workspaces and %d monitors, expected 15 and 3", len(workspaces), len(monitors))
+	}
+
+	id, err := client.GetMouseMonitorID(t.Context())
+	if err != nil {
+		t.Fatalf("GetMouseMonitorID() error = %v", err)
+	}
+	if id != 3 {
+		t.Errorf("GetMouseMonitorID() = %d, expected 3", id)
+	}
+
+	if err := client.SwitchWorkspace(t.Context(), "R2"); err != nil {
+		t.Fatalf("SwitchWorkspace() error = %v", err)
+	}
+
+	if n := server.connections.Load(); n != 1 {
+		t.Errorf("server accepted %d connections, expected 1", n)
+	}
+}
+
+func TestSocketClientNonZeroExit(t *testing.T) {
+	server := startFakeServer(t, map[string]string{})
+
+	client, err := NewSocketClient(t.Context(), server.path)
+	if err != nil {
+		t.Fatalf("NewSocketClient() error = %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SwitchWorkspace(t.Context(), "X9"); err == nil {
+		t.Errorf("SwitchWorkspace() error = nil, expected an error for a failing command")
+	}
+}
+
+func TestSocketClientTimeout(t *testing.T) {
+	server := startFakeServer(t, map[string]string{
+		"workspace B1": "",
+	})
+
+	runner, err := DialSocket(t.Context(), server.path)
+	if err != nil {
+		t.Fatalf("DialSocket() error = %v", err)
+	}
+	defer runner.Close()
+	client := NewClient(runner)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = client.action(ctx, "hang")
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("action() error = %v, expected ErrTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("action() took %v to time out", elapsed)
+	}
+
+	// The interrupted connection must not be reused
+	if err := client.SwitchWorkspace(t.Context(), "B1"); err == nil {
+		t.Errorf("SwitchWorkspace() after a timeout error = nil, expected an error")
+	}
+}
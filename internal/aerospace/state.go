@@ -0,0 +1,179 @@
+package aerospace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrInconsistentState is returned when the queries making up a snapshot disagree
+var ErrInconsistentState = errors.New("inconsistent aerospace state")
+
+// snapshotAttempts is how many times a snapshot is gathered before giving up
+// on consistency, since monitors can change between queries during reconfiguration
+const snapshotAttempts = 2
+
+// State is a single consistent snapshot of Aerospace
+type State struct {
+	Workspaces       []Workspace // All workspaces
+	Monitors         []Monitor   // Monitors ordered from left to right
+	FocusedWorkspace string      // Name of the focused workspace, empty if none
+	MouseMonitorID   int         // ID of the monitor that has the mouse cursor
+	Windows          []Window    // All windows, nil unless requested
+}
+
+// SnapshotOptions selects the optional parts of a State
+type SnapshotOptions struct {
+	Windows bool // Also list all windows
+}
+
+// Snapshot gathers the state of Aerospace with concurrent queries under one
+// context and checks that the results agree with each other
+func (c *Client) Snapshot(ctx context.Context, opts SnapshotOptions) (*State, error) {
+	var err error
+	for range snapshotAttempts {
+		var state *State
+		state, err = c.gather(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		if err = state.Validate(); err == nil {
+			return state, nil
+		}
+	}
+	return nil, err
+}
+
+// gather runs the snapshot queries concurrently, cancelling the rest when one fails
+func (c *Client) gather(ctx context.Context, opts SnapshotOptions) (*State, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		state    State
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	wg.Go(func() {
+		workspaces, err := c.ListWorkspaces(ctx)
+		if err != nil {
+			fail(fmt.Errorf("failed to get workspace info: %w", err))
+			return
+		}
+		state.Workspaces = workspaces
+	})
+	wg.Go(func() {
+		monitors, err := c.ListMonitors(ctx)
+		if err != nil {
+			fail(fmt.Errorf("failed to get monitor info: %w", err))
+			return
+		}
+		state.Monitors = monitors
+	})
+	wg.Go(func() {
+		id, err := c.GetMouseMonitorID(ctx)
+		if err != nil {
+			fail(fmt.Errorf("failed to get mouse monitor: %w", err))
+			return
+		}
+		state.MouseMonitorID = id
+	})
+	if opts.Windows {
+		wg.Go(func() {
+			windows, err := c.ListWindows(ctx)
+			if err != nil {
+				fail(fmt.Errorf("failed to list windows: %w", err))
+				return
+			}
+			if windows == nil {
+				windows = []Window{}
+			}
+			state.Windows = windows
+		})
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	countWorkspaces(state.Monitors, state.Workspaces)
+	for _, ws := range state.Workspaces {
+		if ws.IsFocused {
+			state.FocusedWorkspace = ws.Name
+			break
+		}
+	}
+
+	return &state, nil
+}
+
+// Validate checks that the parts of the snapshot agree with each other
+func (s *State) Validate() error {
+	if s.Monitor(s.MouseMonitorID) == nil {
+		return fmt.Errorf("%w: mouse monitor %d is not among the %d monitors", ErrInconsistentState, s.MouseMonitorID, len(s.Monitors))
+	}
+
+	focused := 0
+	for _, ws := range s.Workspaces {
+		if ws.IsFocused {
+			focused++
+		}
+		if s.Monitor(ws.MonitorID) == nil {
+			return fmt.Errorf("%w: workspace %s is on unknown monitor %d", ErrInconsistentState, ws.Name, ws.MonitorID)
+		}
+	}
+	if focused > 1 {
+		return fmt.Errorf("%w: %d workspaces are focused", ErrInconsistentState, focused)
+	}
+
+	for _, w := range s.Windows {
+		if s.Workspace(w.Workspace) == nil {
+			return fmt.Errorf("%w: window %d is on unknown workspace %s", ErrInconsistentState, w.ID, w.Workspace)
+		}
+	}
+
+	return nil
+}
+
+// Monitor returns the monitor with the given ID, or nil if there is none
+func (s *State) Monitor(id int) *Monitor {
+	for i := range s.Monitors {
+		if s.Monitors[i].ID == id {
+			return &s.Monitors[i]
+		}
+	}
+	return nil
+}
+
+// Workspace returns the workspace with the given name, or nil if there is none
+func (s *State) Workspace(name string) *Workspace {
+	for i := range s.Workspaces {
+		if s.Workspaces[i].Name == name {
+			return &s.Workspaces[i]
+		}
+	}
+	return nil
+}
+
+// VisibleWorkspaceOn returns the name of the workspace visible on the given
+// monitor, or an empty string if there is none
+func (s *State) VisibleWorkspaceOn(monitorID int) string {
+	for _, ws := range s.Workspaces {
+		if ws.MonitorID == monitorID && ws.IsVisible {
+			return ws.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package aerospace
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	client := NewClient(&cannedRunner{outputs: map[string]string{
+		listWorkspacesQuery: listWorkspacesOutput,
+		listMonitorsQuery:   listMonitorsOutput,
+		"list-monitors --mouse --format %{monitor-id}": "2\n",
+		"list-windows --all --format " + windowFormat:  windowRow("4188", "com.mitchellh.ghostty", "B2", "2", "Built-in Retina Display", "Ghostty", "~"),
+	}})
+
+	state, err := client.Snapshot(t.Context(), SnapshotOptions{Windows: true})
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	if len(state.Workspaces) != 15 || len(state.Monitors) != 3 || len(state.Windows) != 1 {
+		t.Errorf("Snapshot() got %d workspaces, %d monitors and %d windows, expected 15, 3 and 1",
+			len(state.Workspaces), len(state.Monitors), len(state.Windows))
+	}
+	if state.FocusedWorkspace != "L1" {
+		t.Errorf("FocusedWorkspace = %q, expected %q", state.FocusedWorkspace, "L1")
+	}
+	if state.MouseMonitorID != 2 {
+		t.Errorf("MouseMonitorID = %d, expected 2", state.MouseMonitorID)
+	}
+	if visible := state.VisibleWorkspaceOn(3); visible != "R1" {
+		t.Errorf("VisibleWorkspaceOn(3) = %q, expected %q", visible, "R1")
+	}
+}
+
+func TestSnapshotWithoutWindows(t *testing.T) {
+	runner := &cannedRunner{outputs: map[string]string{
+		listWorkspacesQuery: listWorkspacesOutput,
+		listMonitorsQuery:   listMonitorsOutput,
+		"list-monitors --mouse --format %{monitor-id}": "1\n",
+	}}
+	client := NewClient(runner)
+
+	state, err := client.Snapshot(t.Context(), SnapshotOptions{})
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if state.Windows != nil {
+		t.Errorf("Windows = %v, expected nil when not requested", state.Windows)
+	}
+}
+
+func TestSnapshotInconsistentMouseMonitor(t *testing.T) {
+	runner := &cannedRunner{outputs: map[string]string{
+		listWorkspacesQuery: listWorkspacesOutput,
+		listMonitorsQuery:   listMonitorsOutput,
+		"list-monitors --mouse --format %{monitor-id}": "4\n",
+	}}
+	client := NewClient(runner)
+
+	_, err := client.Snapshot(t.Context(), SnapshotOptions{})
+	if !errors.Is(err, ErrInconsistentState) {
+		t.Fatalf("Snapshot() error = %v, expected ErrInconsistentState", err)
+	}
+
+	// One version query plus three queries for each attempt
+	if len(runner.calls) != 1+3*snapshotAttempts {
+		t.Errorf("Snapshot() ran %d commands, expected %d", len(runner.calls), 1+3*snapshotAttempts)
+	}
+}
@@ -0,0 +1,96 @@
+package aerospace
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a release version of Aerospace
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// ParseVersion extracts the first x.y.z version number found in s
+func ParseVersion(s string) (Version, error) {
+	match := versionPattern.FindStringSubmatch(s)
+	if match == nil {
+		return Version{}, fmt.Errorf("no version number found in: %s", s)
+	}
+
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	patch, _ := strconv.Atoi(match[3])
+
+	return Version{Major: major, Minor: minor, Patch: patch}, nil
+}
+
+// AtLeast reports whether v is the same as or newer than other
+func (v Version) AtLeast(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor > other.Minor
+	}
+	return v.Patch >= other.Patch
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// serverVersioner is implemented by runners that learn the server version
+// without going through the CLI's --version flag
+type serverVersioner interface {
+	ServerVersion(ctx context.Context) (string, error)
+}
+
+// Version returns the version of the Aerospace server. It is detected on
+// first use and cached for the lifetime of the client.
+func (c *Client) Version(ctx context.Context) (Version, error) {
+	c.versionOnce.Do(func() {
+		c.version, c.versionErr = c.detectVersion(ctx)
+	})
+	return c.version, c.versionErr
+}
+
+// detectVersion asks the runner for the server version
+func (c *Client) detectVersion(ctx context.Context) (Version, error) {
+	if sv, ok := c.runner.(serverVersioner); ok {
+		raw, err := sv.ServerVersion(ctx)
+		if err != nil {
+			return Version{}, fmt.Errorf("failed to get aerospace server version: %w", err)
+		}
+		return ParseVersion(raw)
+	}
+
+	output, err := c.query(ctx, "--version")
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to execute aerospace --version: %w", err)
+	}
+
+	// Prefer the server line, as the server is what answers the list queries
+	text := strings.TrimSpace(string(output))
+	for _, line := range strings.Split(text, "\n") {
+		if strings.Contains(line, "server") {
+			return ParseVersion(line)
+		}
+	}
+	return ParseVersion(text)
+}
+
+// jsonFormat builds the --format argument that selects the fields of --json output
+func jsonFormat(variables ...string) string {
+	parts := make([]string, len(variables))
+	for i, v := range variables {
+		parts[i] = "%{" + v + "}"
+	}
+	return strings.Join(parts, " ")
+}
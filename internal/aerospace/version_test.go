@@ -0,0 +1,52 @@
+package aerospace
+
+import (
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Version
+	}{
+		{input: "0.14.2-Beta 3a8d6a1", expected: Version{Major: 0, Minor: 14, Patch: 2}},
+		{input: "AeroSpace.app server version: 0.18.5-Beta 8d2e2f4", expected: Version{Major: 0, Minor: 18, Patch: 5}},
+		{input: "1.0.0", expected: Version{Major: 1, Minor: 0, Patch: 0}},
+	}
+
+	for _, tt := range tests {
+		v, err := ParseVersion(tt.input)
+		if err != nil {
+			t.Errorf("ParseVersion(%q) error = %v", tt.input, err)
+			continue
+		}
+		if v != tt.expected {
+			t.Errorf("ParseVersion(%q) = %v, expected %v", tt.input, v, tt.expected)
+		}
+	}
+
+	if _, err := ParseVersion("unknown"); err == nil {
+		t.Errorf("ParseVersion(%q) error = nil, expected an error", "unknown")
+	}
+}
+
+func TestVersionDetectedOnce(t *testing.T) {
+	runner := &cannedRunner{outputs: map[string]string{
+		"--version": jsonVersionOutput,
+	}}
+	client := NewClient(runner)
+
+	for range 3 {
+		v, err := client.Version(t.Context())
+		if err != nil {
+			t.Fatalf("Version() error = %v", err)
+		}
+		if v != (Version{Major: 0, Minor: 18, Patch: 5}) {
+			t.Errorf("Version() = %v, expected 0.18.5", v)
+		}
+	}
+
+	if len(runner.calls) != 1 {
+		t.Errorf("Version() ran %d commands, expected 1", len(runner.calls))
+	}
+}
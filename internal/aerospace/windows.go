@@ -0,0 +1,175 @@
+package aerospace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Window represents an Aerospace window with its properties
+type Window struct {
+	ID          int    // Aerospace window ID
+	AppName     string // Name of the owning application
+	BundleID    string // Bundle ID of the owning application
+	Title       string // Title of the window
+	Workspace   string // Name of the workspace the window belongs to
+	MonitorID   int    // 1-based sequential number of the monitor showing the window's workspace
+	MonitorName string // Name of the monitor showing the window's workspace
+}
+
+// windowJSON is a single entry of list-windows --json output
+type windowJSON struct {
+	WindowID    int    `json:"window-id"`
+	AppName     string `json:"app-name"`
+	BundleID    string `json:"app-bundle-id"`
+	Title       string `json:"window-title"`
+	Workspace   string `json:"workspace"`
+	MonitorID   int    `json:"monitor-id"`
+	MonitorName string `json:"monitor-name"`
+}
+
+// windowSeparator separates the fields of windowFormat. Titles, app names and
+// monitor names may hold any printable character, '|' included, but not the
+// ASCII unit separator.
+const windowSeparator = "\x1f"
+
+// windowFormat lists window fields for releases without --json
+const windowFormat = "%{window-id}" + windowSeparator + "%{app-bundle-id}" + windowSeparator + "%{workspace}" + windowSeparator +
+	"%{monitor-id}" + windowSeparator + "%{monitor-name}" + windowSeparator + "%{app-name}" + windowSeparator + "%{window-title}"
+
+// ListWindows returns all windows across all monitors
+func (c *Client) ListWindows(ctx context.Context) ([]Window, error) {
+	return c.listWindows(ctx, "--all")
+}
+
+// ListWindowsOnWorkspace returns the windows that belong to the given workspace
+func (c *Client) ListWindowsOnWorkspace(ctx context.Context, workspaceName string) ([]Window, error) {
+	return c.listWindows(ctx, "--workspace", workspaceName)
+}
+
+// ListWindowsOnMonitor returns the windows on the workspaces of the given monitor
+func (c *Client) ListWindowsOnMonitor(ctx context.Context, monitorID int) ([]Window, error) {
+	return c.listWindows(ctx, "--monitor", strconv.Itoa(monitorID))
+}
+
+// GetFocusedWindow returns the focused window, or nil if no window has focus
+func (c *Client) GetFocusedWindow(ctx context.Context) (*Window, error) {
+	windows, err := c.listWindows(ctx, "--focused")
+	if err != nil {
+		return nil, err
+	}
+	if len(windows) == 0 {
+		return nil, nil
+	}
+	return &windows[0], nil
+}
+
+// listWindows executes the aerospace list-windows command with the given
+// filter, falling back to the legacy format when Aerospace rejects --json
+func (c *Client) listWindows(ctx context.Context, filter ...string) ([]Window, error) {
+	if c.has(CapJSON) {
+		windows, err := c.listWindowsJSON(ctx, filter)
+		if !rejected(err) {
+			return windows, err
+		}
+		c.lacks(CapJSON)
+	}
+
+	windows, err := c.listWindowsLegacy(ctx, filter)
+	if err != nil {
+		return nil, c.explain(ctx, err, CapFormat)
+	}
+	return windows, nil
+}
+
+// listWindowsJSON lists windows using list-windows --json
+func (c *Client) listWindowsJSON(ctx context.Context, filter []string) ([]Window, error) {
+	args := append([]string{"list-windows"}, filter...)
+	args = append(args, "--json", "--format", jsonFormat("window-id", "app-name", "app-bundle-id",
+		"window-title", "workspace", "monitor-id", "monitor-name"))
+
+	output, err := c.query(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute aerospace list-windows: %w", err)
+	}
+
+	var entries []windowJSON
+	if err := json.Unmarshal(output, &entries); err != nil {
+		return nil, &ParseError{Command: "list-windows", Line: string(output), Err: err}
+	}
+
+	windows := make([]Window, 0, len(entries))
+	for _, e := range entries {
+		windows = append(windows, Window{
+			ID:          e.WindowID,
+			AppName:     e.AppName,
+			BundleID:    e.BundleID,
+			Title:       e.Title,
+			Workspace:   e.Workspace,
+			MonitorID:   e.MonitorID,
+			MonitorName: e.MonitorName,
+		})
+	}
+
+	return windows, nil
+}
+
+// listWindowsLegacy lists windows using the separated format understood by
+// Aerospace releases without --json. A row with the wrong number of fields is
+// a *ParseError rather than fields shifted into the wrong place.
+func (c *Client) listWindowsLegacy(ctx context.Context, filter []string) ([]Window, error) {
+	args := append([]string{"list-windows"}, filter...)
+	args = append(args, "--format", windowFormat)
+
+	output, err := c.query(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute aerospace list-windows: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	windows := make([]Window, 0, len(lines))
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		parts := strings.Split(line, windowSeparator)
+		if len(parts) != 7 {
+			return nil, &ParseError{Command: "list-windows", Line: line, Err: fmt.Errorf("expected 7 fields, got %d", len(parts))}
+		}
+
+		id, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, &ParseError{Command: "list-windows", Line: line, Err: fmt.Errorf("invalid window ID: %s", parts[0])}
+		}
+
+		monitorID, err := strconv.Atoi(parts[3])
+		if err != nil {
+			return nil, &ParseError{Command: "list-windows", Line: line, Err: fmt.Errorf("invalid monitor ID: %s", parts[3])}
+		}
+
+		windows = append(windows, Window{
+			ID:          id,
+			BundleID:    parts[1],
+			Workspace:   parts[2],
+			MonitorID:   monitorID,
+			MonitorName: parts[4],
+			AppName:     parts[5],
+			Title:       parts[6],
+		})
+	}
+
+	return windows, nil
+}
+
+// CountWindowsByWorkspace returns the number of windows on each workspace
+func CountWindowsByWorkspace(windows []Window) map[string]int {
+	counts := make(map[string]int)
+	for _, w := range windows {
+		counts[w.Workspace]++
+	}
+	return counts
+}
@@ -0,0 +1,91 @@
+package aerospace
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// windowRow joins the fields of a window as windowFormat prints them
+func windowRow(fields ...string) string {
+	return strings.Join(fields, windowSeparator) + "\n"
+}
+
+var listWindowsOutput = windowRow("4121", "com.apple.Safari", "L1", "1", "XZ272U P (2)", "Safari", "Aerospace | GitHub") +
+	windowRow("4188", "com.mitchellh.ghostty", "B2", "2", "Built-in | Retina", "Ghostty", "~/module") +
+	windowRow("4203", "com.tinyspeck.slackmacgap", "B2", "2", "Built-in | Retina", "Slack | Work", "")
+
+func TestListWindows(t *testing.T) {
+	client := NewClient(&cannedRunner{outputs: map[string]string{
+		"list-windows --all --format " + windowFormat: listWindowsOutput,
+	}})
+
+	windows, err := client.ListWindows(t.Context())
+	if err != nil {
+		t.Fatalf("ListWindows() error = %v", err)
+	}
+
+	expected := []Window{
+		{ID: 4121, AppName: "Safari", BundleID: "com.apple.Safari", Title: "Aerospace | GitHub", Workspace: "L1", MonitorID: 1, MonitorName: "XZ272U P (2)"},
+		{ID: 4188, AppName: "Ghostty", BundleID: "com.mitchellh.ghostty", Title: "~/module", Workspace: "B2", MonitorID: 2, MonitorName: "Built-in | Retina"},
+		{ID: 4203, AppName: "Slack | Work", BundleID: "com.tinyspeck.slackmacgap", Title: "", Workspace: "B2", MonitorID: 2, MonitorName: "Built-in | Retina"},
+	}
+
+	if len(windows) != len(expected) {
+		t.Fatalf("ListWindows() returned %d windows, expected %d", len(windows), len(expected))
+	}
+	for i, w := range windows {
+		if w != expected[i] {
+			t.Errorf("Window[%d] = %+v, expected %+v", i, w, expected[i])
+		}
+	}
+
+	counts := CountWindowsByWorkspace(windows)
+	if counts["B2"] != 2 || counts["L1"] != 1 || counts["R1"] != 0 {
+		t.Errorf("CountWindowsByWorkspace() = %v, expected B2:2 L1:1", counts)
+	}
+}
+
+func TestListWindowsFieldCount(t *testing.T) {
+	client := NewClient(&cannedRunner{outputs: map[string]string{
+		"list-windows --all --format " + windowFormat: windowRow("4121", "com.apple.Safari", "L1", "1", "XZ272U P (2)", "Safari"),
+	}})
+
+	_, err := client.ListWindows(t.Context())
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("ListWindows() error = %v, expected a *ParseError for a missing field", err)
+	}
+}
+
+func TestGetFocusedWindowNone(t *testing.T) {
+	client := NewClient(&cannedRunner{outputs: map[string]string{
+		"list-windows --focused --format " + windowFormat: "",
+	}})
+
+	window, err := client.GetFocusedWindow(t.Context())
+	if err != nil {
+		t.Fatalf("GetFocusedWindow() error = %v", err)
+	}
+	if window != nil {
+		t.Errorf("GetFocusedWindow() = %+v, expected nil", window)
+	}
+}
+
+func TestListWindowsJSON(t *testing.T) {
+	client := NewClient(&cannedRunner{outputs: map[string]string{
+		"list-windows --workspace B2 --json --format %{window-id} %{app-name} %{app-bundle-id} %{window-title} %{workspace} %{monitor-id} %{monitor-name}": `[
+			{"window-id": 4188, "app-name": "Ghostty", "app-bundle-id": "com.mitchellh.ghostty", "window-title": "a | b", "workspace": "B2", "monitor-id": 2, "monitor-name": "Built-in Retina Display"}
+		]`,
+	}})
+
+	windows, err := client.ListWindowsOnWorkspace(t.Context(), "B2")
+	if err != nil {
+		t.Fatalf("ListWindowsOnWorkspace() error = %v", err)
+	}
+
+	expected := Window{ID: 4188, AppName: "Ghostty", BundleID: "com.mitchellh.ghostty", Title: "a | b", Workspace: "B2", MonitorID: 2, MonitorName: "Built-in Retina Display"}
+	if len(windows) != 1 || windows[0] != expected {
+		t.Errorf("ListWindowsOnWorkspace() = %+v, expected [%+v]", windows, expected)
+	}
+}
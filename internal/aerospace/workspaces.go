@@ -1,35 +1,117 @@
 package aerospace
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"os/exec"
 	"strconv"
 	"strings"
 )
 
 // Workspace represents an Aerospace workspace with its properties
 type Workspace struct {
-	Name       string // Name of the workspace
-	IsFocused  bool   // True if the workspace has focus
-	IsVisible  bool   // True if the workspace is visible
-	MonitorID  int    // 1-based sequential number of the belonging monitor
+	Name        string // Name of the workspace
+	IsFocused   bool   // True if the workspace has focus
+	IsVisible   bool   // True if the workspace is visible
+	MonitorID   int    // 1-based sequential number of the belonging monitor
 	MonitorName string // Name of the belonging monitor
 }
 
-// ListWorkspacesAndMonitors executes the aerospace list-workspaces command and returns
-// both workspaces and monitors. Monitors are extracted from the workspace data and
-// ordered from left to right as arranged in macOS settings (by monitor ID).
-func ListWorkspacesAndMonitors() ([]Workspace, []Monitor, error) {
-	cmd := exec.Command("aerospace", "list-workspaces", "--all", "--format",
+// workspaceJSON is a single entry of list-workspaces --json output
+type workspaceJSON struct {
+	Workspace   string `json:"workspace"`
+	IsFocused   bool   `json:"workspace-is-focused"`
+	IsVisible   bool   `json:"workspace-is-visible"`
+	MonitorID   int    `json:"monitor-id"`
+	MonitorName string `json:"monitor-name"`
+}
+
+// ListWorkspacesAndMonitors returns both workspaces and monitors. Monitors come
+// from list-monitors, ordered from left to right as arranged in macOS settings
+// (by monitor ID), with their workspace counts filled in.
+func (c *Client) ListWorkspacesAndMonitors(ctx context.Context) ([]Workspace, []Monitor, error) {
+	workspaces, err := c.ListWorkspaces(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	monitors, err := c.ListMonitors(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	countWorkspaces(monitors, workspaces)
+	return workspaces, monitors, nil
+}
+
+// ListWorkspaces executes the aerospace list-workspaces command and returns
+// all workspaces, falling back to the legacy format when Aerospace rejects --json
+func (c *Client) ListWorkspaces(ctx context.Context) ([]Workspace, error) {
+	if c.has(CapJSON) {
+		workspaces, err := c.listWorkspacesJSON(ctx)
+		if !rejected(err) {
+			return workspaces, err
+		}
+		c.lacks(CapJSON)
+	}
+
+	workspaces, err := c.listWorkspacesLegacy(ctx)
+	if err != nil {
+		return nil, c.explain(ctx, err, CapFormat, CapWorkspaceVisibility)
+	}
+	return workspaces, nil
+}
+
+// countWorkspaces fills in the workspace count of each monitor
+func countWorkspaces(monitors []Monitor, workspaces []Workspace) {
+	for i := range monitors {
+		monitors[i].WorkspaceCount = 0
+		for _, ws := range workspaces {
+			if ws.MonitorID == monitors[i].ID {
+				monitors[i].WorkspaceCount++
+			}
+		}
+	}
+}
+
+// listWorkspacesJSON lists all workspaces using list-workspaces --json
+func (c *Client) listWorkspacesJSON(ctx context.Context) ([]Workspace, error) {
+	output, err := c.query(ctx, "list-workspaces", "--all", "--json", "--format",
+		jsonFormat("workspace", "workspace-is-focused", "workspace-is-visible", "monitor-id", "monitor-name"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute aerospace list-workspaces: %w", err)
+	}
+
+	var entries []workspaceJSON
+	if err := json.Unmarshal(output, &entries); err != nil {
+		return nil, &ParseError{Command: "list-workspaces", Line: string(output), Err: err}
+	}
+
+	workspaces := make([]Workspace, 0, len(entries))
+	for _, e := range entries {
+		workspaces = append(workspaces, Workspace{
+			Name:        e.Workspace,
+			IsFocused:   e.IsFocused,
+			IsVisible:   e.IsVisible,
+			MonitorID:   e.MonitorID,
+			MonitorName: e.MonitorName,
+		})
+	}
+
+	return workspaces, nil
+}
+
+// listWorkspacesLegacy lists all workspaces using the pipe-delimited format
+// understood by Aerospace releases without --json
+func (c *Client) listWorkspacesLegacy(ctx context.Context) ([]Workspace, error) {
+	output, err := c.query(ctx, "list-workspaces", "--all", "--format",
 		"%{workspace}|%{workspace-is-focused}|%{workspace-is-visible}|%{monitor-id}|%{monitor-name}")
-	output, err := cmd.Output()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to execute aerospace list-workspaces: %w", err)
+		return nil, fmt.Errorf("failed to execute aerospace list-workspaces: %w", err)
 	}
 
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	workspaces := make([]Workspace, 0, len(lines))
-	monitorMap := make(map[int]string) // ID -> Name
 
 	for _, line := range lines {
 		if line == "" {
@@ -38,22 +120,22 @@ func ListWorkspacesAndMonitors() ([]Workspace, []Monitor, error) {
 
 		parts := strings.SplitN(line, "|", 5)
 		if len(parts) != 5 {
-			return nil, nil, fmt.Errorf("invalid workspace output format: %s", line)
+			return nil, &ParseError{Command: "list-workspaces", Line: line, Err: fmt.Errorf("expected 5 fields, got %d", len(parts))}
 		}
 
 		isFocused, err := strconv.ParseBool(parts[1])
 		if err != nil {
-			return nil, nil, fmt.Errorf("invalid workspace-is-focused value: %s", parts[1])
+			return nil, &ParseError{Command: "list-workspaces", Line: line, Err: fmt.Errorf("invalid workspace-is-focused value: %s", parts[1])}
 		}
 
 		isVisible, err := strconv.ParseBool(parts[2])
 		if err != nil {
-			return nil, nil, fmt.Errorf("invalid workspace-is-visible value: %s", parts[2])
+			return nil, &ParseError{Command: "list-workspaces", Line: line, Err: fmt.Errorf("invalid workspace-is-visible value: %s", parts[2])}
 		}
 
 		monitorID, err := strconv.Atoi(parts[3])
 		if err != nil {
-			return nil, nil, fmt.Errorf("invalid monitor ID: %s", parts[3])
+			return nil, &ParseError{Command: "list-workspaces", Line: line, Err: fmt.Errorf("invalid monitor ID: %s", parts[3])}
 		}
 
 		workspaces = append(workspaces, Workspace{
@@ -63,37 +145,15 @@ func ListWorkspacesAndMonitors() ([]Workspace, []Monitor, error) {
 			MonitorID:   monitorID,
 			MonitorName: parts[4],
 		})
-
-		// Collect unique monitors
-		monitorMap[monitorID] = parts[4]
-	}
-
-	// Convert monitor map to sorted slice (by ID, which represents left-to-right order)
-	monitors := make([]Monitor, 0, len(monitorMap))
-	for id := 1; id <= len(monitorMap); id++ {
-		if name, exists := monitorMap[id]; exists {
-			monitors = append(monitors, Monitor{
-				ID:   id,
-				Name: name,
-			})
-		}
 	}
 
-	return workspaces, monitors, nil
+	return workspaces, nil
 }
 
 // SwitchWorkspace switches to a specific workspace by name
-func SwitchWorkspace(workspaceName string) error {
-	cmd := exec.Command("aerospace", "workspace", workspaceName)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to switch to workspace %s: %w (output: %s)", workspaceName, err, string(output))
+func (c *Client) SwitchWorkspace(ctx context.Context, workspaceName string) error {
+	if err := c.action(ctx, "workspace", workspaceName); err != nil {
+		return fmt.Errorf("failed to switch to workspace %s: %w", workspaceName, err)
 	}
-
-	// If there's any output, something likely went wrong
-	if len(output) > 0 {
-		return fmt.Errorf("unexpected output while switching to workspace %s: %s", workspaceName, string(output))
-	}
-
 	return nil
 }
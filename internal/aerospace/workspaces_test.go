@@ -4,8 +4,32 @@ import (
 	"testing"
 )
 
+const listWorkspacesQuery = "list-workspaces --all --format %{workspace}|%{workspace-is-focused}|%{workspace-is-visible}|%{monitor-id}|%{monitor-name}"
+
+const listWorkspacesOutput = `L1|true|true|1|XZ272U P (2)
+L2|false|false|1|XZ272U P (2)
+L3|false|false|1|XZ272U P (2)
+L4|false|false|1|XZ272U P (2)
+L5|false|false|1|XZ272U P (2)
+B1|false|true|2|Built-in Retina Display
+B2|false|false|2|Built-in Retina Display
+B3|false|false|2|Built-in Retina Display
+B4|false|false|2|Built-in Retina Display
+B5|false|false|2|Built-in Retina Display
+R1|false|true|3|XZ272U P (1)
+R2|false|false|3|XZ272U P (1)
+R3|false|false|3|XZ272U P (1)
+R4|false|false|3|XZ272U P (1)
+R5|false|false|3|XZ272U P (1)
+`
+
 func TestListWorkspacesAndMonitors(t *testing.T) {
-	workspaces, monitors, err := ListWorkspacesAndMonitors()
+	client := NewClient(&cannedRunner{outputs: map[string]string{
+		listWorkspacesQuery: listWorkspacesOutput,
+		listMonitorsQuery:   listMonitorsOutput,
+	}})
+
+	workspaces, monitors, err := client.ListWorkspacesAndMonitors(t.Context())
 	if err != nil {
 		t.Fatalf("ListWorkspacesAndMonitors() error = %v", err)
 	}
@@ -29,11 +53,11 @@ func TestListWorkspacesAndMonitors(t *testing.T) {
 		{Name: "R5", IsFocused: false, IsVisible: false, MonitorID: 3, MonitorName: "XZ272U P (1)"},
 	}
 
-	// Expected monitors extracted from workspace data
+	// Expected monitors with workspace counts filled in
 	expectedMonitors := []Monitor{
-		{ID: 1, Name: "XZ272U P (2)"},
-		{ID: 2, Name: "Built-in Retina Display"},
-		{ID: 3, Name: "XZ272U P (1)"},
+		{ID: 1, Name: "XZ272U P (2)", AppKitID: 2, WorkspaceCount: 5},
+		{ID: 2, Name: "Built-in Retina Display", IsMain: true, AppKitID: 1, WorkspaceCount: 5},
+		{ID: 3, Name: "XZ272U P (1)", AppKitID: 3, WorkspaceCount: 5},
 	}
 
 	// Test workspaces
@@ -73,11 +97,69 @@ func TestListWorkspacesAndMonitors(t *testing.T) {
 			break
 		}
 		exp := expectedMonitors[i]
-		if mon.ID != exp.ID {
-			t.Errorf("Monitor[%d].ID = %d, expected %d", i, mon.ID, exp.ID)
+		if mon != exp {
+			t.Errorf("Monitor[%d] = %+v, expected %+v", i, mon, exp)
 		}
-		if mon.Name != exp.Name {
-			t.Errorf("Monitor[%d].Name = %q, expected %q", i, mon.Name, exp.Name)
+	}
+}
+
+func TestListWorkspacesAndMonitorsJSON(t *testing.T) {
+	client := NewClient(&cannedRunner{outputs: map[string]string{
+		"list-workspaces --all --json --format %{workspace} %{workspace-is-focused} %{workspace-is-visible} %{monitor-id} %{monitor-name}": `[
+			{"workspace": "L1", "workspace-is-focused": true, "workspace-is-visible": true, "monitor-id": 1, "monitor-name": "Studio | Left"},
+			{"workspace": "B1", "workspace-is-focused": false, "workspace-is-visible": true, "monitor-id": 2, "monitor-name": "Built-in Retina Display", "workspace-layout": "tiles"}
+		]`,
+		"list-monitors --json --format %{monitor-id} %{monitor-name} %{monitor-is-main} %{monitor-appkit-nsscreen-screens-id}": `[
+			{"monitor-id": 2, "monitor-name": "Built-in Retina Display", "monitor-is-main": true, "monitor-appkit-nsscreen-screens-id": 1},
+			{"monitor-id": 1, "monitor-name": "Studio | Left", "monitor-is-main": false, "monitor-appkit-nsscreen-screens-id": 2}
+		]`,
+	}})
+
+	workspaces, monitors, err := client.ListWorkspacesAndMonitors(t.Context())
+	if err != nil {
+		t.Fatalf("ListWorkspacesAndMonitors() error = %v", err)
+	}
+
+	expectedWorkspaces := []Workspace{
+		{Name: "L1", IsFocused: true, IsVisible: true, MonitorID: 1, MonitorName: "Studio | Left"},
+		{Name: "B1", IsFocused: false, IsVisible: true, MonitorID: 2, MonitorName: "Built-in Retina Display"},
+	}
+	if len(workspaces) != len(expectedWorkspaces) {
+		t.Fatalf("Got %d workspaces, expected %d", len(workspaces), len(expectedWorkspaces))
+	}
+	for i, ws := range workspaces {
+		if ws != expectedWorkspaces[i] {
+			t.Errorf("Workspace[%d] = %+v, expected %+v", i, ws, expectedWorkspaces[i])
+		}
+	}
+
+	if len(monitors) != 2 || monitors[0].Name != "Studio | Left" {
+		t.Errorf("Monitors = %+v, expected the pipe to survive in the first monitor name", monitors)
+	}
+}
+
+func TestListWorkspacesAndMonitorsNonContiguousIDs(t *testing.T) {
+	client := NewClient(&cannedRunner{outputs: map[string]string{
+		listWorkspacesQuery: "B1|true|true|1|Built-in Retina Display\nR1|false|true|3|DELL U2720Q\n",
+		listMonitorsQuery:   "3|false|3|DELL U2720Q\n1|true|1|Built-in Retina Display\n2|false|2|LG HDR 4K\n",
+	}})
+
+	_, monitors, err := client.ListWorkspacesAndMonitors(t.Context())
+	if err != nil {
+		t.Fatalf("ListWorkspacesAndMonitors() error = %v", err)
+	}
+
+	expected := []Monitor{
+		{ID: 1, Name: "Built-in Retina Display", IsMain: true, AppKitID: 1, WorkspaceCount: 1},
+		{ID: 2, Name: "LG HDR 4K", AppKitID: 2, WorkspaceCount: 0},
+		{ID: 3, Name: "DELL U2720Q", AppKitID: 3, WorkspaceCount: 1},
+	}
+	if len(monitors) != len(expected) {
+		t.Fatalf("Got %d monitors, expected %d", len(monitors), len(expected))
+	}
+	for i, mon := range monitors {
+		if mon != expected[i] {
+			t.Errorf("Monitor[%d] = %+v, expected %+v", i, mon, expected[i])
 		}
 	}
 }
@@ -0,0 +1,509 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Config declares workspace roles and how they are assigned to monitors
+type Config struct {
+	Roles   []Role           `json:"roles"`
+	Layouts []Layout         `json:"layouts"`
+	BuiltIn []Match          `json:"builtin,omitempty"` // Rules identifying the built-in display, first matching rule wins
+	Docked  Docked           `json:"docked"`
+	Mapping string           `json:"mapping,omitempty"` // Default mapping strategy for number keys, relative when empty
+	Keys    []string         `json:"keys,omitempty"`    // Workspaces reached by keys 1-9 and 0 with absolute mapping, ten per page
+	Aliases map[string]Alias `json:"aliases,omitempty"`
+	Switch  string           `json:"switch,omitempty"` // What the workspace command does with a workspace on another monitor, focus when empty
+	// Physical arrangement of monitors, overriding the ID order Aerospace reports
+	Placement []Placement `json:"placement,omitempty"`
+	// Monitors that come and go, such as Sidecar or AirPlay screens
+	Transient Transient `json:"transient"`
+	// Layouts for known monitor sets, taking precedence over the per-count layouts
+	Profiles []Profile `json:"profiles,omitempty"`
+	Profile  string    `json:"profile,omitempty"` // Name of a profile to use whatever monitors are connected
+}
+
+// Placement puts a monitor next to another one, such as a display stacked above the laptop
+type Placement struct {
+	Monitor Match  `json:"monitor"`
+	Side    string `json:"side"` // One of the Side* constants
+	Of      Match  `json:"of"`
+}
+
+// Sides a monitor can be placed on relative to another one
+const (
+	SideLeft  = "left"
+	SideRight = "right"
+	SideAbove = "above"
+	SideBelow = "below"
+)
+
+// Alias names a slot of a role, such as chat for the second workspace of B
+type Alias struct {
+	Role string `json:"role"`
+	Slot int    `json:"slot"` // 1-based position within the role's workspaces
+}
+
+// Policies for switching to a workspace that lives on another monitor than the mouse
+const (
+	SwitchFocus = "focus" // Focus the workspace on the monitor holding it
+	SwitchPull  = "pull"  // Move the workspace to the monitor with the mouse
+)
+
+// Mapping strategies for number keys
+const (
+	MappingRelative = "relative" // Keys pick from the roles of the monitor under the mouse
+	MappingAbsolute = "absolute" // Keys always reach the same workspace, wherever it lives
+	MappingHybrid   = "hybrid"   // Keys 1-5 are relative, 6-0 absolute
+)
+
+// Docked configures role assignment while no built-in display is connected,
+// such as with the lid closed
+type Docked struct {
+	Primary *Match `json:"primary,omitempty"` // External display standing in for the built-in one
+}
+
+// Transient configures monitors that come and go. They get no roles, don't
+// count toward the layout and each shows a workspace of its own.
+type Transient struct {
+	Monitors  []Match `json:"monitors,omitempty"`  // Rules picking transient monitors, DefaultTransient when unset
+	Workspace string  `json:"workspace,omitempty"` // Workspace of the first transient monitor, the next ones get 2, 3, ... appended
+}
+
+// DefaultTransientWorkspace is the workspace of the first transient monitor
+// unless configured otherwise
+const DefaultTransientWorkspace = "T"
+
+// Role is a named set of workspaces that always live on the same monitor
+type Role struct {
+	Name       string   `json:"name"`
+	Workspaces []string `json:"workspaces,omitempty"`
+	Pattern    string   `json:"pattern,omitempty"` // Generates workspace names, {n} is replaced by 1 to Count
+	Count      int      `json:"count,omitempty"`
+}
+
+// PageSize is the number of workspaces of a role reached by one half of the number keys
+const PageSize = 5
+
+// Layout assigns roles to monitors when a given number of monitors is connected
+type Layout struct {
+	Monitors int          `json:"monitors"`          // Number of connected monitors the layout applies to
+	Assign   []Assignment `json:"assign"`            // At most one entry per monitor, resolved in order
+	Focus    []string     `json:"focus"`             // Workspaces shown after rearranging, the last one keeps focus
+	Mapping  string       `json:"mapping,omitempty"` // Overrides the config's mapping strategy for this layout
+}
+
+// Assignment gives roles to the monitor picked by a rule
+type Assignment struct {
+	Monitor Match    `json:"monitor"` // Rule or shorthand name picking the monitor
+	Roles   []string `json:"roles"`   // Keys 1-5 reach the first role, 6-0 the second (or the first again)
+}
+
+// Default returns the configuration matching the built-in behavior: B1-B5 on
+// the built-in display, L1-L5 and R1-R5 on the external displays
+func Default() *Config {
+	return &Config{
+		Roles: []Role{
+			{Name: "L", Workspaces: []string{"L1", "L2", "L3", "L4", "L5"}},
+			{Name: "B", Workspaces: []string{"B1", "B2", "B3", "B4", "B5"}},
+			{Name: "R", Workspaces: []string{"R1", "R2", "R3", "R4", "R5"}},
+		},
+		Layouts: []Layout{
+			{
+				Monitors: 1,
+				Assign:   []Assignment{{Monitor: MatchAny, Roles: []string{"L", "R", "B"}}},
+				Focus:    []string{"B1"},
+			},
+			{
+				Monitors: 2,
+				Assign: []Assignment{
+					{Monitor: MatchBuiltIn, Roles: []string{"B"}},
+					{Monitor: MatchExternal, Roles: []string{"L", "R"}},
+				},
+				Focus: []string{"B1", "L1"},
+			},
+			{
+				Monitors: 3,
+				Assign: []Assignment{
+					{Monitor: MatchBuiltIn, Roles: []string{"B"}},
+					{Monitor: MatchExternal, Roles: []string{"L"}},
+					{Monitor: MatchExternal, Roles: []string{"R"}},
+				},
+				Focus: []string{"B1", "L1", "R1"},
+			},
+		},
+	}
+}
+
+// DefaultPath returns where the config file lives: $XDG_CONFIG_HOME/aeromanager/config.json,
+// falling back to ~/.config/aeromanager/config.json
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "aeromanager", "config.json"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "aeromanager", "config.json"), nil
+}
+
+// Load reads the config file at path, returning the default configuration
+// when the file doesn't exist
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes and validates a JSON config
+func Parse(data []byte) (*Config, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.expand(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// expand generates the workspace names of roles declared with a pattern
+func (c *Config) expand() error {
+	for i := range c.Roles {
+		role := &c.Roles[i]
+		if role.Pattern == "" {
+			continue
+		}
+		if len(role.Workspaces) > 0 {
+			return fmt.Errorf("role %s has both workspaces and a pattern", role.Name)
+		}
+		if !strings.Contains(role.Pattern, "{n}") {
+			return fmt.Errorf("role %s: pattern %q has no {n}", role.Name, role.Pattern)
+		}
+		if role.Count < 1 {
+			return fmt.Errorf("role %s: pattern needs a count", role.Name)
+		}
+		for n := 1; n <= role.Count; n++ {
+			role.Workspaces = append(role.Workspaces, strings.ReplaceAll(role.Pattern, "{n}", strconv.Itoa(n)))
+		}
+	}
+	return nil
+}
+
+// Validate checks that roles are well formed and layouts only refer to known roles
+func (c *Config) Validate() error {
+	roles := make(map[string]bool)
+	owners := make(map[string]string)
+	for _, role := range c.Roles {
+		if role.Name == "" {
+			return fmt.Errorf("role without a name")
+		}
+		if roles[role.Name] {
+			return fmt.Errorf("duplicate role %s", role.Name)
+		}
+		roles[role.Name] = true
+
+		if len(role.Workspaces) == 0 {
+			return fmt.Errorf("role %s has no workspaces", role.Name)
+		}
+		for _, ws := range role.Workspaces {
+			if owner, exists := owners[ws]; exists {
+				return fmt.Errorf("workspace %s belongs to both role %s and role %s", ws, owner, role.Name)
+			}
+			owners[ws] = role.Name
+		}
+	}
+
+	for _, rule := range c.BuiltIn {
+		if err := rule.validate(false); err != nil {
+			return fmt.Errorf("builtin: %w", err)
+		}
+	}
+
+	if c.Docked.Primary != nil {
+		if err := c.Docked.Primary.validate(false); err != nil {
+			return fmt.Errorf("docked primary: %w", err)
+		}
+	}
+
+	for _, rule := range c.Transient.Monitors {
+		if err := rule.validate(false); err != nil {
+			return fmt.Errorf("transient: %w", err)
+		}
+		if rule.usesPosition() {
+			return fmt.Errorf("transient: monitors are matched by identity, not position")
+		}
+	}
+	// Any number of transient monitors may connect, so every name they could get is off limits
+	for _, role := range c.Roles {
+		for _, ws := range role.Workspaces {
+			if c.isTransientWorkspace(ws) {
+				return fmt.Errorf("transient workspace %s is also a workspace of role %s", ws, role.Name)
+			}
+		}
+	}
+
+	if err := validateMapping(c.Mapping); err != nil {
+		return err
+	}
+	for _, ws := range c.Keys {
+		if _, exists := owners[ws]; !exists {
+			return fmt.Errorf("key workspace %s belongs to no role", ws)
+		}
+	}
+
+	for alias, target := range c.Aliases {
+		role := c.Role(target.Role)
+		if role == nil {
+			return fmt.Errorf("alias %s: unknown role %s", alias, target.Role)
+		}
+		if target.Slot < 1 || target.Slot > len(role.Workspaces) {
+			return fmt.Errorf("alias %s: role %s has no slot %d", alias, role.Name, target.Slot)
+		}
+		if owner, exists := owners[alias]; exists {
+			return fmt.Errorf("alias %s is also a workspace of role %s", alias, owner)
+		}
+	}
+	switch c.Switch {
+	case "", SwitchFocus, SwitchPull:
+	default:
+		return fmt.Errorf("unknown switch policy %q (must be focus or pull)", c.Switch)
+	}
+
+	for i, p := range c.Placement {
+		switch p.Side {
+		case SideLeft, SideRight, SideAbove, SideBelow:
+		default:
+			return fmt.Errorf("placement %d: unknown side %q (must be left, right, above or below)", i+1, p.Side)
+		}
+		for _, m := range []Match{p.Monitor, p.Of} {
+			if err := m.validate(true); err != nil {
+				return fmt.Errorf("placement %d: %w", i+1, err)
+			}
+			if m.usesPosition() {
+				return fmt.Errorf("placement %d: monitors are placed by identity, not position", i+1)
+			}
+		}
+	}
+
+	counts := make(map[int]bool)
+	for _, layout := range c.Layouts {
+		if layout.Monitors < 1 {
+			return fmt.Errorf("layout for %d monitors", layout.Monitors)
+		}
+		if counts[layout.Monitors] {
+			return fmt.Errorf("duplicate layout for %d monitors", layout.Monitors)
+		}
+		counts[layout.Monitors] = true
+
+		if err := layout.validate(roles, owners); err != nil {
+			return fmt.Errorf("layout for %d monitors: %w", layout.Monitors, err)
+		}
+	}
+
+	return c.validateProfiles(roles, owners)
+}
+
+// validate checks the layout's assignments, roles holds the known role names
+// and owners the role owning each workspace
+func (l Layout) validate(roles map[string]bool, owners map[string]string) error {
+	if err := validateMapping(l.Mapping); err != nil {
+		return err
+	}
+	if len(l.Assign) > l.Monitors {
+		return fmt.Errorf("assigns %d monitors", len(l.Assign))
+	}
+	for _, a := range l.Assign {
+		if err := a.Monitor.validate(true); err != nil {
+			return err
+		}
+		if len(a.Roles) == 0 {
+			return fmt.Errorf("%s monitor has no roles", a.Monitor)
+		}
+		for _, name := range a.Roles {
+			if !roles[name] {
+				return fmt.Errorf("unknown role %s", name)
+			}
+		}
+	}
+	for _, ws := range l.Focus {
+		if _, exists := owners[ws]; !exists {
+			return fmt.Errorf("focus workspace %s belongs to no role", ws)
+		}
+	}
+	return nil
+}
+
+// BuiltInRules returns the rules identifying the built-in display
+func (c *Config) BuiltInRules() []Match {
+	if len(c.BuiltIn) == 0 {
+		return DefaultBuiltIn
+	}
+	return c.BuiltIn
+}
+
+// validateMapping checks a mapping strategy name, empty meaning the default
+func validateMapping(mapping string) error {
+	switch mapping {
+	case "", MappingRelative, MappingAbsolute, MappingHybrid:
+		return nil
+	}
+	return fmt.Errorf("unknown mapping %q (must be relative, absolute or hybrid)", mapping)
+}
+
+// MappingFor returns the mapping strategy used with a layout
+func (c *Config) MappingFor(layout *Layout) string {
+	if layout != nil && layout.Mapping != "" {
+		return layout.Mapping
+	}
+	if c.Mapping != "" {
+		return c.Mapping
+	}
+	return MappingRelative
+}
+
+// DockedPrimary returns the rule picking the external display that takes
+// over the built-in display's roles while docked
+func (c *Config) DockedPrimary() Match {
+	if c.Docked.Primary == nil {
+		return DefaultDockedPrimary
+	}
+	return *c.Docked.Primary
+}
+
+// TransientRules returns the rules picking transient monitors. An explicitly
+// empty list turns the defaults off.
+func (c *Config) TransientRules() []Match {
+	if c.Transient.Monitors == nil {
+		return DefaultTransient
+	}
+	return c.Transient.Monitors
+}
+
+// TransientWorkspace returns the workspace of the nth transient monitor, 1-based
+func (c *Config) TransientWorkspace(n int) string {
+	name := c.Transient.Workspace
+	if name == "" {
+		name = DefaultTransientWorkspace
+	}
+	if n > 1 {
+		name += strconv.Itoa(n)
+	}
+	return name
+}
+
+// isTransientWorkspace reports whether TransientWorkspace returns name for some n
+func (c *Config) isTransientWorkspace(name string) bool {
+	base := c.TransientWorkspace(1)
+	if name == base {
+		return true
+	}
+	suffix, found := strings.CutPrefix(name, base)
+	if !found {
+		return false
+	}
+	n, err := strconv.Atoi(suffix)
+	return err == nil && n > 1 && strconv.Itoa(n) == suffix
+}
+
+// Role returns the role with the given name, or nil if there is none
+func (c *Config) Role(name string) *Role {
+	for i := range c.Roles {
+		if c.Roles[i].Name == name {
+			return &c.Roles[i]
+		}
+	}
+	return nil
+}
+
+// Layout returns the layout for the given number of monitors, or nil if there is none
+func (c *Config) Layout(monitors int) *Layout {
+	for i := range c.Layouts {
+		if c.Layouts[i].Monitors == monitors {
+			return &c.Layouts[i]
+		}
+	}
+	return nil
+}
+
+// LayoutFor returns the layout declared for the given number of monitors, or
+// one generated from the role order when none is declared: roles are handed
+// out one per monitor from left to right, the last monitor takes any roles
+// left over, and monitors beyond the number of roles get none
+func (c *Config) LayoutFor(monitors int) *Layout {
+	if layout := c.Layout(monitors); layout != nil {
+		return layout
+	}
+	if monitors < 1 || len(c.Roles) == 0 {
+		return nil
+	}
+
+	layout := &Layout{Monitors: monitors}
+	for i, role := range c.Roles {
+		if i < monitors {
+			layout.Assign = append(layout.Assign, Assignment{Monitor: MatchAny})
+			layout.Focus = append(layout.Focus, role.Workspaces[0])
+		}
+		last := &layout.Assign[len(layout.Assign)-1]
+		last.Roles = append(last.Roles, role.Name)
+	}
+	return layout
+}
+
+// ResolveWorkspace returns the workspace an alias stands for, or the name
+// itself when it is not an alias
+func (c *Config) ResolveWorkspace(name string) string {
+	alias, ok := c.Aliases[name]
+	if !ok {
+		return name
+	}
+	if role := c.Role(alias.Role); role != nil && alias.Slot >= 1 && alias.Slot <= len(role.Workspaces) {
+		return role.Workspaces[alias.Slot-1]
+	}
+	return name
+}
+
+// SwitchPolicy returns the policy for switching to a workspace on another monitor
+func (c *Config) SwitchPolicy() string {
+	if c.Switch == "" {
+		return SwitchFocus
+	}
+	return c.Switch
+}
+
+// RoleOf returns the role owning a workspace, or nil if it belongs to no role
+func (c *Config) RoleOf(workspace string) *Role {
+	for i := range c.Roles {
+		for _, ws := range c.Roles[i].Workspaces {
+			if ws == workspace {
+				return &c.Roles[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultIsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("Default().Validate() error = %v", err)
+	}
+}
+
+func TestLoadMissingFileUsesDefault(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Roles) != len(Default().Roles) {
+		t.Errorf("Load() returned %d roles, expected the %d default roles", len(cfg.Roles), len(Default().Roles))
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+		"roles": [
+			{"name": "main", "workspaces": ["web", "code", "chat"]},
+			{"name": "side", "workspaces": ["music", "notes"]}
+		],
+		"layouts": [
+			{"monitors": 1, "assign": [{"monitor": "any", "roles": ["main", "side"]}], "focus": ["web"]},
+			{"monitors": 2, "assign": [
+				{"monitor": "builtin", "roles": ["side"]},
+				{"monitor": "external", "roles": ["main"]}
+			], "focus": ["music", "web"]}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if role := cfg.RoleOf("chat"); role == nil || role.Name != "main" {
+		t.Errorf("RoleOf(chat) = %v, expected main", role)
+	}
+	if layout := cfg.Layout(2); layout == nil || layout.Assign[1].Roles[0] != "main" {
+		t.Errorf("Layout(2) = %v, expected main on the external monitor", layout)
+	}
+	if layout := cfg.Layout(3); layout != nil {
+		t.Errorf("Layout(3) = %v, expected nil", layout)
+	}
+}
+
+func TestLayoutFor(t *testing.T) {
+	cfg := &Config{Roles: []Role{
+		{Name: "a", Workspaces: []string{"a1"}},
+		{Name: "b", Workspaces: []string{"b1"}},
+		{Name: "c", Workspaces: []string{"c1"}},
+	}}
+
+	tests := []struct {
+		monitors int
+		expected [][]string // Roles of each assignment
+	}{
+		{monitors: 1, expected: [][]string{{"a", "b", "c"}}},
+		{monitors: 2, expected: [][]string{{"a"}, {"b", "c"}}},
+		{monitors: 3, expected: [][]string{{"a"}, {"b"}, {"c"}}},
+		{monitors: 5, expected: [][]string{{"a"}, {"b"}, {"c"}}},
+	}
+
+	for _, tt := range tests {
+		layout := cfg.LayoutFor(tt.monitors)
+		if layout == nil {
+			t.Fatalf("LayoutFor(%d) = nil", tt.monitors)
+		}
+		var got [][]string
+		for _, a := range layout.Assign {
+			got = append(got, a.Roles)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+			t.Errorf("LayoutFor(%d) assigns %v, expected %v", tt.monitors, got, tt.expected)
+		}
+	}
+
+	if layout := Default().LayoutFor(2); layout.Assign[0].Monitor.String() != SelectBuiltIn {
+		t.Errorf("LayoutFor(2) ignored the declared layout")
+	}
+}
+
+func TestParseMatch(t *testing.T) {
+	data := `{
+		"roles": [{"name": "a", "workspaces": ["1"]}, {"name": "b", "workspaces": ["2"]}],
+		"layouts": [{"monitors": 2, "assign": [
+			{"monitor": "external", "roles": ["a"]},
+			{"monitor": {"name": "DELL", "position": "rightmost", "fallback": [{"main": true}, "any"]}, "roles": ["b"]}
+		]}]
+	}`
+
+	cfg, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	expected := []string{SelectExternal, `name=~"DELL", position=rightmost | main=true | any`}
+	for i, a := range cfg.Layouts[0].Assign {
+		if got := a.Monitor.String(); got != expected[i] {
+			t.Errorf("Assign[%d].Monitor = %s, expected %s", i, got, expected[i])
+		}
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	cfg, err := Parse([]byte(`{"roles": [{"name": "B", "pattern": "B{n}", "count": 12}]}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	ws := cfg.Roles[0].Workspaces
+	if len(ws) != 12 || ws[0] != "B1" || ws[9] != "B10" || ws[11] != "B12" {
+		t.Errorf("Workspaces = %v, expected B1 to B12", ws)
+	}
+	if role := cfg.RoleOf("B10"); role == nil {
+		t.Errorf("RoleOf(B10) = nil, expected B")
+	}
+}
+
+func TestResolveWorkspace(t *testing.T) {
+	cfg := Default()
+	cfg.Aliases = map[string]Alias{"chat": {Role: "B", Slot: 2}}
+
+	for name, expected := range map[string]string{"chat": "B2", "L4": "L4", "scratch": "scratch"} {
+		if got := cfg.ResolveWorkspace(name); got != expected {
+			t.Errorf("ResolveWorkspace(%s) = %s, expected %s", name, got, expected)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string // Substring of the error
+	}{
+		{
+			name:     "unknown field",
+			data:     `{"roles": [], "layout": []}`,
+			expected: "unknown field",
+		},
+		{
+			name:     "duplicate workspace",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}, {"name": "b", "workspaces": ["1"]}]}`,
+			expected: "belongs to both",
+		},
+		{
+			name:     "unknown role",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "layouts": [{"monitors": 1, "assign": [{"monitor": "any", "roles": ["b"]}]}]}`,
+			expected: "unknown role b",
+		},
+		{
+			name:     "unknown selector",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "layouts": [{"monitors": 1, "assign": [{"monitor": "left", "roles": ["a"]}]}]}`,
+			expected: "unknown monitor selector",
+		},
+		{
+			name:     "assignment count mismatch",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "layouts": [{"monitors": 1, "assign": [{"monitor": "any", "roles": ["a"]}, {"monitor": "any", "roles": ["a"]}]}]}`,
+			expected: "assigns 2 monitors",
+		},
+		{
+			name:     "invalid name pattern",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "layouts": [{"monitors": 1, "assign": [{"monitor": {"name": "("}, "roles": ["a"]}]}]}`,
+			expected: "invalid name pattern",
+		},
+		{
+			name:     "invalid position",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "layouts": [{"monitors": 1, "assign": [{"monitor": {"position": "middle"}, "roles": ["a"]}]}]}`,
+			expected: "invalid position",
+		},
+		{
+			name:     "circular built-in rule",
+			data:     `{"roles": [], "builtin": [{"name": "Studio"}, {"builtin": true}]}`,
+			expected: "can't depend on the built-in display",
+		},
+		{
+			name:     "pattern without placeholder",
+			data:     `{"roles": [{"name": "a", "pattern": "A", "count": 3}]}`,
+			expected: "has no {n}",
+		},
+		{
+			name:     "pattern and workspaces",
+			data:     `{"roles": [{"name": "a", "workspaces": ["A1"], "pattern": "A{n}", "count": 3}]}`,
+			expected: "both workspaces and a pattern",
+		},
+		{
+			name:     "alias to a missing slot",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "aliases": {"chat": {"role": "a", "slot": 2}}}`,
+			expected: "has no slot 2",
+		},
+		{
+			name:     "alias shadowing a workspace",
+			data:     `{"roles": [{"name": "a", "workspaces": ["chat"]}], "aliases": {"chat": {"role": "a", "slot": 1}}}`,
+			expected: "also a workspace",
+		},
+		{
+			name:     "unknown switch policy",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "switch": "swap"}`,
+			expected: "unknown switch policy",
+		},
+		{
+			name:     "unknown mapping",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "layouts": [{"monitors": 1, "assign": [{"monitor": "any", "roles": ["a"]}], "mapping": "spatial"}]}`,
+			expected: "unknown mapping",
+		},
+		{
+			name:     "key outside roles",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "mapping": "absolute", "keys": ["1", "2"]}`,
+			expected: "key workspace 2",
+		},
+		{
+			name:     "focus outside roles",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "layouts": [{"monitors": 1, "assign": [{"monitor": "any", "roles": ["a"]}], "focus": ["2"]}]}`,
+			expected: "belongs to no role",
+		},
+		{
+			name:     "unknown placement side",
+			data:     `{"roles": [], "placement": [{"monitor": {"name": "LG"}, "side": "behind", "of": "builtin"}]}`,
+			expected: "unknown side",
+		},
+		{
+			name:     "placement by position",
+			data:     `{"roles": [], "placement": [{"monitor": {"position": "leftmost"}, "side": "above", "of": "builtin"}]}`,
+			expected: "placed by identity",
+		},
+		{
+			name:     "transient by position",
+			data:     `{"roles": [], "transient": {"monitors": [{"position": "rightmost"}]}}`,
+			expected: "matched by identity",
+		},
+		{
+			name:     "transient workspace owned by a role",
+			data:     `{"roles": [{"name": "a", "workspaces": ["T"]}]}`,
+			expected: "transient workspace T",
+		},
+		{
+			name:     "role workspace named like a later transient workspace",
+			data:     `{"roles": [{"name": "a", "workspaces": ["T1", "T2"]}]}`,
+			expected: "transient workspace T2 is also a workspace of role a",
+		},
+		{
+			name:     "profile without monitors",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "profiles": [{"name": "home", "assign": [{"monitor": "any", "roles": ["a"]}]}]}`,
+			expected: "profile home has no monitors",
+		},
+		{
+			name:     "profile with an unknown role",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "profiles": [{"name": "home", "monitors": ["LG"], "assign": [{"monitor": "any", "roles": ["b"]}]}]}`,
+			expected: "profile home: unknown role b",
+		},
+		{
+			name:     "duplicate profile",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "profiles": [{"name": "home", "monitors": ["LG"], "assign": []}, {"name": "home", "monitors": ["DELL"], "assign": []}]}`,
+			expected: "duplicate profile home",
+		},
+		{
+			name:     "unknown forced profile",
+			data:     `{"roles": [{"name": "a", "workspaces": ["1"]}], "profile": "office"}`,
+			expected: "unknown profile office",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Parse() error = %v, expected it to contain %q", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatalf("DefaultPath() error = %v", err)
+	}
+	if path != "/tmp/xdg/aeromanager/config.json" {
+		t.Errorf("DefaultPath() = %s, expected /tmp/xdg/aeromanager/config.json", path)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "/home/user")
+	path, err = DefaultPath()
+	if err != nil {
+		t.Fatalf("DefaultPath() error = %v", err)
+	}
+	if path != "/home/user/.config/aeromanager/config.json" {
+		t.Errorf("DefaultPath() = %s, expected /home/user/.config/aeromanager/config.json", path)
+	}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Monitor rule shorthands accepted in place of a Match object
+const (
+	SelectBuiltIn  = "builtin"  // The built-in display
+	SelectExternal = "external" // The next external display, left to right
+	SelectAny      = "any"      // The next unassigned display, left to right
+)
+
+// Monitor positions understood by Match.Position besides a 1-based index
+const (
+	PositionLeftmost  = "leftmost"
+	PositionRightmost = "rightmost"
+)
+
+// Match is a rule picking a monitor. Every condition that is set must hold and
+// the leftmost free monitor satisfying them is picked. When no monitor does,
+// the fallback rules are tried in order.
+type Match struct {
+	Name     string  `json:"name,omitempty"`     // Regular expression matched against the monitor name
+	Label    string  `json:"label,omitempty"`    // Label given to the physical monitor with aeromanager label
+	Position string  `json:"position,omitempty"` // "leftmost", "rightmost" or a 1-based index from the left
+	Main     *bool   `json:"main,omitempty"`     // Whether the monitor is the macOS main display
+	BuiltIn  *bool   `json:"builtin,omitempty"`  // Whether the monitor is identified as built-in
+	Fallback []Match `json:"fallback,omitempty"` // Rules tried in order when this one picks nothing
+}
+
+var (
+	yes = true
+	no  = false
+
+	// MatchBuiltIn picks the built-in display
+	MatchBuiltIn = Match{BuiltIn: &yes}
+	// MatchExternal picks the leftmost free external display
+	MatchExternal = Match{BuiltIn: &no}
+	// MatchAny picks the leftmost free display
+	MatchAny = Match{}
+)
+
+// DefaultBuiltIn identifies built-in displays by the names macOS gives them
+// across models and system languages
+var DefaultBuiltIn = []Match{
+	{Name: `(?i)built-?in|color lcd|intégr|integr|内蔵|内建|內建|내장`},
+}
+
+// DefaultTransient picks iPad Sidecar and AirPlay screens, which are usually
+// connected for a moment
+var DefaultTransient = []Match{
+	{Name: `(?i)sidecar|airplay`},
+}
+
+// DefaultDockedPrimary stands the main display in for the built-in one while docked
+var DefaultDockedPrimary = Match{Main: &yes, Fallback: []Match{{Position: PositionLeftmost}}}
+
+// UnmarshalJSON accepts either a rule object or one of the shorthand names
+func (m *Match) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		switch name {
+		case SelectBuiltIn:
+			*m = MatchBuiltIn
+		case SelectExternal:
+			*m = MatchExternal
+		case SelectAny:
+			*m = MatchAny
+		default:
+			return fmt.Errorf("unknown monitor selector %q", name)
+		}
+		return nil
+	}
+
+	type plain Match
+	return json.Unmarshal(data, (*plain)(m))
+}
+
+// String describes the rule, using a shorthand name when there is one
+func (m Match) String() string {
+	var parts []string
+	if m.Name != "" {
+		parts = append(parts, fmt.Sprintf("name=~%q", m.Name))
+	}
+	if m.Label != "" {
+		parts = append(parts, fmt.Sprintf("label=%q", m.Label))
+	}
+	if m.Position != "" {
+		parts = append(parts, "position="+m.Position)
+	}
+	if m.Main != nil {
+		parts = append(parts, fmt.Sprintf("main=%t", *m.Main))
+	}
+	if m.BuiltIn != nil {
+		if len(parts) == 0 && len(m.Fallback) == 0 {
+			if *m.BuiltIn {
+				return SelectBuiltIn
+			}
+			return SelectExternal
+		}
+		parts = append(parts, fmt.Sprintf("builtin=%t", *m.BuiltIn))
+	}
+	if len(parts) == 0 && len(m.Fallback) == 0 {
+		return SelectAny
+	}
+
+	s := strings.Join(parts, ", ")
+	for _, f := range m.Fallback {
+		s += " | " + f.String()
+	}
+	return s
+}
+
+// validate checks the rule and its fallbacks, builtIn allows the built-in condition
+func (m Match) validate(builtIn bool) error {
+	if m.Name != "" {
+		if _, err := regexp.Compile(m.Name); err != nil {
+			return fmt.Errorf("invalid name pattern %q: %w", m.Name, err)
+		}
+	}
+	switch m.Position {
+	case "", PositionLeftmost, PositionRightmost:
+	default:
+		if n, err := strconv.Atoi(m.Position); err != nil || n < 1 {
+			return fmt.Errorf("invalid position %q (must be leftmost, rightmost or a number from 1)", m.Position)
+		}
+	}
+	if m.BuiltIn != nil && !builtIn {
+		return fmt.Errorf("built-in display rules can't depend on the built-in display")
+	}
+	for _, f := range m.Fallback {
+		if err := f.validate(builtIn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usesPosition reports whether the rule or any fallback depends on monitor order
+func (m Match) usesPosition() bool {
+	if m.Position != "" {
+		return true
+	}
+	for _, f := range m.Fallback {
+		if f.usesPosition() {
+			return true
+		}
+	}
+	return false
+}
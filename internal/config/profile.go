@@ -0,0 +1,247 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Profile is the layout for a known set of monitors, such as the home desk or
+// the office. It is picked when exactly its monitors are connected, ignoring
+// transient ones.
+type Profile struct {
+	Name     string       `json:"name"`
+	Monitors []string     `json:"monitors"` // Monitor names without the " (n)" suffix macOS adds to identical monitors, in any order
+	Assign   []Assignment `json:"assign"`
+	Focus    []string     `json:"focus,omitempty"`   // Workspaces focused in order after rearranging, the last one keeps focus
+	Mapping  string       `json:"mapping,omitempty"` // Mapping strategy while the profile is active, the config default when empty
+}
+
+// Layout returns the profile as a layout for its monitor count
+func (p *Profile) Layout() *Layout {
+	return &Layout{Monitors: len(p.Monitors), Assign: p.Assign, Focus: p.Focus, Mapping: p.Mapping}
+}
+
+// Matches reports whether the profile's monitor set is exactly the given
+// monitor names, in any order
+func (p *Profile) Matches(names []string) bool {
+	want, got := slices.Clone(p.Monitors), slices.Clone(names)
+	slices.Sort(want)
+	slices.Sort(got)
+	return slices.Equal(want, got)
+}
+
+// FindProfile returns the profile with the given name, or nil if there is none
+func (c *Config) FindProfile(name string) *Profile {
+	for i := range c.Profiles {
+		if c.Profiles[i].Name == name {
+			return &c.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// ProfileFor returns the profile in use with the given monitor names: the
+// forced profile when one is set, otherwise the first profile matching the
+// monitor set, or nil when none does
+func (c *Config) ProfileFor(names []string) *Profile {
+	if c.Profile != "" {
+		return c.FindProfile(c.Profile)
+	}
+	for i := range c.Profiles {
+		if c.Profiles[i].Matches(names) {
+			return &c.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// validateProfiles checks the profiles like layouts and that the forced profile exists
+func (c *Config) validateProfiles(roles map[string]bool, owners map[string]string) error {
+	names := make(map[string]bool)
+	for _, p := range c.Profiles {
+		if p.Name == "" {
+			return fmt.Errorf("profile without a name")
+		}
+		if names[p.Name] {
+			return fmt.Errorf("duplicate profile %s", p.Name)
+		}
+		names[p.Name] = true
+
+		if len(p.Monitors) == 0 {
+			return fmt.Errorf("profile %s has no monitors", p.Name)
+		}
+		if err := p.Layout().validate(roles, owners); err != nil {
+			return fmt.Errorf("profile %s: %w", p.Name, err)
+		}
+	}
+
+	if c.Profile != "" && !names[c.Profile] {
+		return fmt.Errorf("unknown profile %s", c.Profile)
+	}
+	return nil
+}
+
+// SaveProfile adds the profile to the config file at path, replacing the
+// profile with the same name. Only the profiles array changes, everything
+// else keeps its order and formatting, and a missing file starts out as the
+// built-in configuration.
+func SaveProfile(path string, p Profile) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if data, err = json.MarshalIndent(Default(), "", "  "); err == nil {
+			data = append(data, '\n')
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	data, err = spliceProfile(data, p)
+	if err != nil {
+		return fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	// Never leave a config behind that aeromanager refuses to load
+	if _, err := Parse(data); err != nil {
+		return fmt.Errorf("profile %s: %w", p.Name, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// spliceProfile writes the profile into the config's profiles array in
+// place, indented like its surroundings
+func spliceProfile(data []byte, p Profile) ([]byte, error) {
+	fields, closing, err := members(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	fieldIndent := "  "
+	if len(fields) > 0 {
+		fieldIndent = lineIndent(data, fields[0].start, fieldIndent)
+	}
+
+	var profiles *span
+	for i := range fields {
+		if fields[i].key == "profiles" {
+			profiles = &fields[i]
+		}
+	}
+	if profiles == nil {
+		encoded, err := json.MarshalIndent(p, fieldIndent+"  ", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode profile: %w", err)
+		}
+		insert := fmt.Sprintf("\n%s\"profiles\": [\n%s  %s\n%s]", fieldIndent, fieldIndent, encoded, fieldIndent)
+		if len(fields) == 0 {
+			return splice(data, closing, closing, insert+"\n"), nil
+		}
+		last := fields[len(fields)-1].end
+		return splice(data, last, last, ","+insert), nil
+	}
+
+	elements, _, err := members(data, profiles.start)
+	if err != nil {
+		return nil, err
+	}
+	elementIndent := fieldIndent + "  "
+	if len(elements) > 0 {
+		elementIndent = lineIndent(data, elements[0].start, elementIndent)
+	}
+	encoded, err := json.MarshalIndent(p, elementIndent, "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode profile: %w", err)
+	}
+
+	for _, e := range elements {
+		var existing struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data[e.start:e.end], &existing) == nil && existing.Name == p.Name {
+			return splice(data, e.start, e.end, string(encoded)), nil
+		}
+	}
+	if len(elements) == 0 {
+		return splice(data, profiles.start, profiles.end, fmt.Sprintf("[\n%s%s\n%s]", elementIndent, encoded, fieldIndent)), nil
+	}
+	last := elements[len(elements)-1].end
+	return splice(data, last, last, ",\n"+elementIndent+string(encoded)), nil
+}
+
+// span is where a member of a JSON object or array sits in the encoded bytes
+type span struct {
+	key        string // Member name, empty for array elements
+	start, end int
+}
+
+// members returns the members of the JSON object or array starting at
+// data[offset], and the offset of its closing bracket
+func members(data []byte, offset int) ([]span, int, error) {
+	dec := json.NewDecoder(bytes.NewReader(data[offset:]))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, 0, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok || (delim != '{' && delim != '[') {
+		return nil, 0, fmt.Errorf("expected an object or array at offset %d", offset)
+	}
+
+	var spans []span
+	for dec.More() {
+		var key string
+		if delim == '{' {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, 0, err
+			}
+			key, _ = tok.(string)
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, 0, err
+		}
+		end := offset + int(dec.InputOffset())
+		spans = append(spans, span{key: key, start: end - len(raw), end: end})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, 0, err
+	}
+	return spans, offset + int(dec.InputOffset()) - 1, nil
+}
+
+// lineIndent returns the leading whitespace of the line holding data[pos],
+// or fallback when it is on the first line
+func lineIndent(data []byte, pos int, fallback string) string {
+	start := bytes.LastIndexByte(data[:pos], '\n') + 1
+	if start == 0 {
+		return fallback
+	}
+	end := start
+	for end < pos && (data[end] == ' ' || data[end] == '\t') {
+		end++
+	}
+	return string(data[start:end])
+}
+
+// splice returns data with data[start:end] replaced by text
+func splice(data []byte, start, end int, text string) []byte {
+	out := make([]byte, 0, len(data)+len(text))
+	out = append(out, data[:start]...)
+	out = append(out, text...)
+	return append(out, data[end:]...)
+}
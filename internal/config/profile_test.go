@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfileFor(t *testing.T) {
+	cfg := Default()
+	cfg.Profiles = []Profile{
+		{Name: "home", Monitors: []string{"Built-in Retina Display", "LG HDR WQHD"}},
+		{Name: "office", Monitors: []string{"DELL U2720Q", "Built-in Retina Display", "DELL U2720Q"}},
+	}
+
+	tests := []struct {
+		names    []string
+		forced   string
+		expected string // Empty for no profile
+	}{
+		{names: []string{"LG HDR WQHD", "Built-in Retina Display"}, expected: "home"},
+		{names: []string{"DELL U2720Q", "DELL U2720Q", "Built-in Retina Display"}, expected: "office"},
+		{names: []string{"DELL U2720Q", "Built-in Retina Display"}},
+		{names: []string{"Built-in Retina Display", "LG HDR WQHD", "LG HDR WQHD"}},
+		{names: []string{"DELL U2720Q"}, forced: "home", expected: "home"},
+	}
+
+	for _, tt := range tests {
+		cfg.Profile = tt.forced
+		got := cfg.ProfileFor(tt.names)
+		if tt.expected == "" {
+			if got != nil {
+				t.Errorf("ProfileFor(%v) = %s, expected none", tt.names, got.Name)
+			}
+			continue
+		}
+		if got == nil || got.Name != tt.expected {
+			t.Errorf("ProfileFor(%v) = %v, expected %s", tt.names, got, tt.expected)
+		}
+	}
+}
+
+func TestSaveProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+		"roles": [{"name": "a", "workspaces": ["1", "2"]}],
+		"layouts": [{"monitors": 1, "assign": [{"monitor": "builtin", "roles": ["a"]}]}],
+		"profiles": [{"name": "office", "monitors": ["DELL"], "assign": [{"monitor": "any", "roles": ["a"]}]}]
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	home := Profile{Name: "home", Monitors: []string{"LG"}, Assign: []Assignment{{Monitor: MatchAny, Roles: []string{"a"}}}}
+	if err := SaveProfile(path, home); err != nil {
+		t.Fatalf("SaveProfile() error = %v", err)
+	}
+	home.Focus = []string{"2"}
+	if err := SaveProfile(path, home); err != nil {
+		t.Fatalf("SaveProfile() error = %v", err)
+	}
+	if err := SaveProfile(path, Profile{Name: "broken", Monitors: []string{"LG"}, Focus: []string{"3"}}); err == nil {
+		t.Errorf("SaveProfile() of an invalid profile succeeded, expected an error")
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Profiles) != 2 || cfg.Profiles[0].Name != "office" || cfg.Profiles[1].Name != "home" {
+		t.Fatalf("Profiles = %v, expected office and home", cfg.Profiles)
+	}
+	if focus := cfg.Profiles[1].Focus; len(focus) != 1 || focus[0] != "2" {
+		t.Errorf("home Focus = %v, expected the replaced profile's [2]", focus)
+	}
+
+	// Everything but the new profile stays as written, in order and formatting
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	office := `{"name": "office", "monitors": ["DELL"], "assign": [{"monitor": "any", "roles": ["a"]}]}`
+	kept := data[:strings.Index(data, office)+len(office)]
+	if !strings.HasPrefix(string(written), kept) || !strings.HasSuffix(string(written), "}]\n\t}") {
+		t.Errorf("SaveProfile() rewrote the rest of the config:\n%s", written)
+	}
+}
+
+func TestSaveProfileWithoutConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aeromanager", "config.json")
+	p := Profile{Name: "home", Monitors: []string{"LG"}, Assign: []Assignment{{Monitor: MatchAny, Roles: []string{"B"}}}}
+	if err := SaveProfile(path, p); err != nil {
+		t.Fatalf("SaveProfile() error = %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Roles) != len(Default().Roles) || cfg.FindProfile("home") == nil {
+		t.Errorf("Load() = %d roles and profiles %v, expected the default roles and home", len(cfg.Roles), cfg.Profiles)
+	}
+}
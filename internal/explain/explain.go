@@ -0,0 +1,134 @@
+package explain
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+	"github.com/Xkonti/aeromanager/internal/topology"
+	"github.com/Xkonti/aeromanager/internal/workspacemap"
+)
+
+// Execute prints how the current monitor setup is understood and what a
+// target resolves to. A number target (1-5 or 6-0) is mapped for the monitor
+// with the mouse on the given page, 0 meaning its current page; any other
+// target is taken as a workspace alias or name. Either way the keys reaching
+// the resulting workspace are listed.
+func Execute(ctx context.Context, client *aerospace.Client, cfg *config.Config, st *store.Store, w io.Writer, target string, page int) error {
+	workspaceNum, err := strconv.Atoi(target)
+	isNum := err == nil
+	if isNum && (workspaceNum < 0 || workspaceNum > 10) {
+		return fmt.Errorf("invalid workspace number: %d (must be 1-5 or 6-0)", workspaceNum)
+	}
+
+	state, topo, err := topology.Snapshot(ctx, client, cfg, st, w)
+	if err != nil {
+		return err
+	}
+	strategy := workspacemap.Strategy(cfg, topo)
+
+	PrintTopology(w, cfg, topo)
+
+	mouse := state.Monitor(state.MouseMonitorID)
+	pages := strategy.Pages(mouse.ID, topo)
+	if page == 0 {
+		page = st.Page(st.PageKey(state.Monitors, mouse.ID), pages)
+	}
+	fmt.Fprintf(w, "Mouse: monitor %d %q, page %d of %d\n", mouse.ID, mouse.Name, page, pages)
+
+	workspace := cfg.ResolveWorkspace(target)
+	if isNum {
+		workspace, err = strategy.Map(workspaceNum, page, mouse.ID, topo)
+		if err != nil {
+			fmt.Fprintf(w, "Key %d: %v\n", workspaceNum, err)
+			return nil
+		}
+		fmt.Fprintf(w, "Key %d resolves to %s\n", workspaceNum, workspace)
+	} else if workspace != target {
+		fmt.Fprintf(w, "Alias %s stands for %s\n", target, workspace)
+	}
+
+	if ws := state.Workspace(workspace); ws != nil {
+		fmt.Fprintf(w, "%s currently lives on monitor %d\n", workspace, ws.MonitorID)
+	} else {
+		fmt.Fprintf(w, "%s does not exist in Aerospace\n", workspace)
+	}
+	PrintBindings(w, strategy, workspace, topo)
+
+	return nil
+}
+
+// PrintTopology prints the monitors, the roles assigned to each and why
+func PrintTopology(w io.Writer, cfg *config.Config, topo *topology.Topology) {
+	source := fmt.Sprintf("layout for %d monitors", len(topo.Monitors))
+	if topo.Generated {
+		source = "no layout, roles handed out left to right"
+	}
+	if topo.Profile != nil {
+		source = "profile " + topo.Profile.Name
+		if cfg.Profile != "" && topo.Warning == "" {
+			source += " (forced)"
+		}
+	}
+	fmt.Fprintf(w, "Topology: %d monitors, %s, %s mapping\n", len(topo.Monitors), source, cfg.MappingFor(topo.Layout))
+	if topo.Docked {
+		fmt.Fprintf(w, "Docked: no built-in display, monitor %d stands in for it\n", topo.Primary)
+	}
+
+	for _, mon := range topo.Monitors {
+		details := flags(mon)
+		if label, ok := topo.Labels[mon.ID]; ok {
+			details += fmt.Sprintf(" labeled %q", label)
+		}
+		if len(cfg.Placement) > 0 {
+			p := topo.Points[mon.ID]
+			details += fmt.Sprintf(" at (%d, %d)", p.X, p.Y)
+		}
+		fmt.Fprintf(w, "  Monitor %d %q%s: ", mon.ID, mon.Name, details)
+		assigned := false
+		for _, a := range topo.Assignments {
+			if a.Monitor.ID != mon.ID {
+				continue
+			}
+			names := make([]string, 0, len(a.Roles))
+			for _, role := range a.Roles {
+				names = append(names, role.Name)
+			}
+			fmt.Fprintf(w, "%s (%s)\n", strings.Join(names, ", "), a.Reason)
+			assigned = true
+		}
+		if !assigned {
+			fmt.Fprintf(w, "no roles\n")
+		}
+	}
+	for _, tr := range topo.Transient {
+		fmt.Fprintf(w, "  Monitor %d %q%s: transient, workspace %s\n", tr.Monitor.ID, tr.Monitor.Name, flags(tr.Monitor), tr.Workspace)
+	}
+}
+
+// PrintBindings prints every key and monitor combination reaching the workspace
+func PrintBindings(w io.Writer, strategy workspacemap.MappingStrategy, workspace string, topo *topology.Topology) {
+	bindings := workspacemap.Bindings(strategy, workspace, topo)
+	if len(bindings) == 0 {
+		fmt.Fprintf(w, "No key reaches %s\n", workspace)
+		return
+	}
+
+	fmt.Fprintf(w, "%s is reached by:\n", workspace)
+	for _, b := range bindings {
+		fmt.Fprintf(w, "  key %d on page %d with the mouse on monitor %d\n", b.WorkspaceNum, b.Page, b.MonitorID)
+	}
+}
+
+// flags describes notable properties of a monitor
+func flags(mon aerospace.Monitor) string {
+	if mon.IsMain {
+		return " (main)"
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package explain
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/aerospace/sim"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+)
+
+func TestExecute(t *testing.T) {
+	cfg := config.Default()
+	cfg.Aliases = map[string]config.Alias{"chat": {Role: "B", Slot: 2}}
+
+	tests := []struct {
+		name     string
+		target   string
+		expected string
+	}{
+		{
+			name:   "number",
+			target: "7",
+			expected: `Topology: 2 monitors, layout for 2 monitors, relative mapping
+  Monitor 1 "Built-in Retina Display" (main): B (matched builtin)
+  Monitor 2 "DELL U2720Q": L, R (matched external)
+Mouse: monitor 2 "DELL U2720Q", page 1 of 1
+Key 7 resolves to R2
+R2 currently lives on monitor 2
+R2 is reached by:
+  key 7 on page 1 with the mouse on monitor 2
+`,
+		},
+		{
+			name:   "alias",
+			target: "chat",
+			expected: `Topology: 2 monitors, layout for 2 monitors, relative mapping
+  Monitor 1 "Built-in Retina Display" (main): B (matched builtin)
+  Monitor 2 "DELL U2720Q": L, R (matched external)
+Mouse: monitor 2 "DELL U2720Q", page 1 of 1
+Alias chat stands for B2
+B2 currently lives on monitor 1
+B2 is reached by:
+  key 2 on page 1 with the mouse on monitor 1
+  key 7 on page 1 with the mouse on monitor 1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := sim.New(
+				sim.Monitor{Name: "Built-in Retina Display", IsMain: true, Workspaces: sim.Names("B", 5)},
+				sim.Monitor{Name: "DELL U2720Q", Workspaces: append(sim.Names("L", 5), sim.Names("R", 5)...)},
+			)
+			server.SetMouse(2)
+			st, err := store.Open(filepath.Join(t.TempDir(), "state.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out strings.Builder
+			if err := Execute(t.Context(), aerospace.NewClient(server), cfg, st, &out, tt.target, 0); err != nil {
+				t.Fatalf("Execute(%s) error = %v", tt.target, err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("Execute(%s) printed:\n%s\nexpected:\n%s", tt.target, out.String(), tt.expected)
+			}
+			if len(server.Log()) != 0 {
+				t.Errorf("Execute() ran %v, expected no commands", server.Log())
+			}
+		})
+	}
+}
+
+func TestExecuteDocked(t *testing.T) {
+	server := sim.New(
+		sim.Monitor{Name: "DELL U2720Q", IsMain: true, Workspaces: sim.Names("B", 5)},
+		sim.Monitor{Name: "DELL U2720Q (2)", Workspaces: append(sim.Names("L", 5), sim.Names("R", 5)...)},
+		sim.Monitor{Name: "LG HDR 4K", Workspaces: []string{"X1"}},
+	)
+	st, err := store.Open(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := Execute(t.Context(), aerospace.NewClient(server), config.Default(), st, &out, "1", 0); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	for _, expected := range []string{
+		"Docked: no built-in display, monitor 1 stands in for it",
+		`Monitor 1 "DELL U2720Q" (main): B (matched builtin, standing in for the built-in display while docked)`,
+		"Key 1 resolves to B1",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Execute() printed:\n%s\nexpected it to contain %q", out.String(), expected)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package hyprmonitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+	"github.com/Xkonti/aeromanager/internal/topology"
+)
+
+// directions maps command arguments to placement sides
+var directions = map[string]string{
+	"left":  config.SideLeft,
+	"right": config.SideRight,
+	"up":    config.SideAbove,
+	"down":  config.SideBelow,
+}
+
+// Execute focuses the monitor next to the one with the mouse in the given
+// direction (left, right, up or down), following the configured placement
+func Execute(ctx context.Context, client *aerospace.Client, cfg *config.Config, st *store.Store, direction string) error {
+	side, ok := directions[direction]
+	if !ok {
+		return fmt.Errorf("invalid direction: %s (must be left, right, up or down)", direction)
+	}
+
+	state, topo, err := topology.Snapshot(ctx, client, cfg, st, os.Stdout)
+	if err != nil {
+		return err
+	}
+	mouseMonitorID := state.MouseMonitorID
+
+	target, ok := topo.Neighbor(mouseMonitorID, side)
+	if !ok {
+		return fmt.Errorf("no monitor %s of monitor %d", direction, mouseMonitorID)
+	}
+
+	targetWorkspace := state.VisibleWorkspaceOn(target.ID)
+	if targetWorkspace == "" {
+		return fmt.Errorf("no visible workspace found on monitor %d", target.ID)
+	}
+
+	fmt.Printf("Focusing monitor %d (%s) showing workspace %s\n", target.ID, target.Name, targetWorkspace)
+
+	// Switch to the target workspace
+	if err := client.SwitchWorkspace(ctx, targetWorkspace); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package hyprmonitor
+
+import (
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/aerospace/sim"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+)
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name      string
+		placement []config.Placement
+		mouse     int
+		direction string
+		expected  string // Focused workspace afterwards, empty for an error
+	}{
+		{name: "right in ID order", mouse: 1, direction: "right", expected: "L1"},
+		{name: "left in ID order", mouse: 2, direction: "left", expected: "B1"},
+		{name: "nothing to the left", mouse: 1, direction: "left"},
+		{
+			name:      "up follows placement",
+			placement: []config.Placement{{Monitor: config.MatchExternal, Side: config.SideAbove, Of: config.MatchBuiltIn}},
+			mouse:     1,
+			direction: "up",
+			expected:  "L1",
+		},
+		{
+			name:      "placement replaces ID order",
+			placement: []config.Placement{{Monitor: config.MatchExternal, Side: config.SideAbove, Of: config.MatchBuiltIn}},
+			mouse:     1,
+			direction: "right",
+		},
+		{name: "invalid direction", mouse: 1, direction: "sideways"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Placement = tt.placement
+
+			server := sim.New(
+				sim.Monitor{Name: "Built-in Retina Display", IsMain: true, Workspaces: sim.Names("B", 5)},
+				sim.Monitor{Name: "DELL U2720Q", Workspaces: append(sim.Names("L", 5), sim.Names("R", 5)...)},
+			)
+			server.SetMouse(tt.mouse)
+
+			err := Execute(t.Context(), aerospace.NewClient(server), cfg, &store.Store{}, tt.direction)
+			if tt.expected == "" {
+				if err == nil {
+					t.Fatalf("Execute(%s) succeeded, expected an error", tt.direction)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute(%s) error = %v", tt.direction, err)
+			}
+			if got := server.FocusedWorkspace(); got != tt.expected {
+				t.Errorf("Execute(%s) focused %q, expected %q", tt.direction, got, tt.expected)
+			}
+		})
+	}
+}
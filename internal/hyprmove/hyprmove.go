@@ -1,33 +1,41 @@
 package hyprmove
 
 import (
+	"context"
 	"fmt"
+	"os"
 
 	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+	"github.com/Xkonti/aeromanager/internal/topology"
 	"github.com/Xkonti/aeromanager/internal/workspacemap"
 )
 
 // Execute performs intelligent window movement based on cursor position
 // If workspaceNum is -1, moves the window to the visible workspace on the monitor with the mouse
 // Otherwise, moves the window to the workspace that corresponds to the given number
-func Execute(workspaceNum int) error {
-	// Get current workspace and monitor configuration
-	workspaces, monitors, err := aerospace.ListWorkspacesAndMonitors()
-	if err != nil {
-		return fmt.Errorf("failed to get workspace and monitor info: %w", err)
+// on the given page, where a page of 0 uses the remembered page of the monitor with
+// the mouse and any other page is remembered as that monitor's current page
+func Execute(ctx context.Context, client *aerospace.Client, cfg *config.Config, st *store.Store, workspaceNum int, page int) error {
+	if page < 0 {
+		return fmt.Errorf("invalid page: %d", page)
 	}
 
-	// Get the monitor where the mouse cursor is
-	mouseMonitorID, err := aerospace.GetMouseMonitorID()
+	state, topo, err := topology.Snapshot(ctx, client, cfg, st, os.Stdout)
 	if err != nil {
-		return fmt.Errorf("failed to get mouse monitor: %w", err)
+		return err
 	}
+	mouseMonitorID := state.MouseMonitorID
+	mouseMonitor := st.PageKey(state.Monitors, mouseMonitorID)
+
+	explicitPage := page != 0 && workspaceNum != -1
 
 	var targetWorkspace string
 
 	if workspaceNum == -1 {
 		// Move to the visible workspace on the monitor with the mouse
-		targetWorkspace = findVisibleWorkspaceOnMonitor(mouseMonitorID, workspaces)
+		targetWorkspace = state.VisibleWorkspaceOn(mouseMonitorID)
 		if targetWorkspace == "" {
 			return fmt.Errorf("no visible workspace found on monitor %d", mouseMonitorID)
 		}
@@ -38,50 +46,37 @@ func Execute(workspaceNum int) error {
 			return fmt.Errorf("invalid workspace number: %d (must be 1-5 or 6-0)", workspaceNum)
 		}
 
-		// Determine which workspace to move the window to based on monitor count and cursor position
-		targetWorkspace = workspacemap.MapWorkspaceNumber(workspaceNum, mouseMonitorID, monitors)
-
-		if len(monitors) > 3 {
-			return fmt.Errorf("unsupported monitor configuration: %d monitors", len(monitors))
+		// Determine which workspace to move the window to based on the configured roles and cursor position
+		if page == 0 {
+			page = st.Page(mouseMonitor, workspacemap.Strategy(cfg, topo).Pages(mouseMonitorID, topo))
+		}
+		targetWorkspace, err = workspacemap.MapWorkspaceNumber(workspaceNum, page, mouseMonitorID, cfg, topo)
+		if err != nil {
+			return err
 		}
 
-		// Validate that the target workspace exists
-		if !workspaceExists(targetWorkspace, workspaces) {
-			return fmt.Errorf("workspace %s does not exist", targetWorkspace)
+		// Validate that the target workspace exists, absolute mapping may pick one on another monitor
+		ws := state.Workspace(targetWorkspace)
+		if ws == nil {
+			return fmt.Errorf("workspace %s does not exist: %w", targetWorkspace, aerospace.ErrUnknownWorkspace)
 		}
 
-		fmt.Printf("Moving focused window to workspace %s on monitor %d\n", targetWorkspace, mouseMonitorID)
+		fmt.Printf("Moving focused window to workspace %s on monitor %d\n", targetWorkspace, ws.MonitorID)
 	}
 
 	// Move the focused window to the target workspace
-	if err := aerospace.MoveNodeToWorkspace(targetWorkspace, false); err != nil {
+	if err := client.MoveNodeToWorkspace(ctx, targetWorkspace, false); err != nil {
 		return fmt.Errorf("failed to move window to workspace %s: %w", targetWorkspace, err)
 	}
 
 	// Switch to the target workspace to keep the window focused and ready for work
-	if err := aerospace.SwitchWorkspace(targetWorkspace); err != nil {
+	if err := client.SwitchWorkspace(ctx, targetWorkspace); err != nil {
 		return fmt.Errorf("failed to switch to workspace %s: %w", targetWorkspace, err)
 	}
 
-	return nil
-}
-
-// findVisibleWorkspaceOnMonitor finds the visible workspace on a specific monitor
-func findVisibleWorkspaceOnMonitor(monitorID int, workspaces []aerospace.Workspace) string {
-	for _, ws := range workspaces {
-		if ws.MonitorID == monitorID && ws.IsVisible {
-			return ws.Name
-		}
+	if explicitPage {
+		st.SetPage(mouseMonitor, page)
+		return st.Save()
 	}
-	return ""
+	return nil
 }
-
-// workspaceExists checks if a workspace with the given name exists
-func workspaceExists(name string, workspaces []aerospace.Workspace) bool {
-	for _, ws := range workspaces {
-		if ws.Name == name {
-			return true
-		}
-	}
-	return false
-}
\ No newline at end of file
@@ -0,0 +1,47 @@
+package hyprmove
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/aerospace/sim"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+)
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name     string
+		mouse    int
+		num      int
+		expected string // Workspace the window ends up on, focused afterwards
+	}{
+		{name: "visible workspace under the mouse", mouse: 1, num: -1, expected: "B1"},
+		{name: "numbered on built-in", mouse: 1, num: 8, expected: "B3"},
+		{name: "numbered on external", mouse: 2, num: 8, expected: "R3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := sim.New(
+				sim.Monitor{Name: "Built-in Retina Display", IsMain: true, Workspaces: sim.Names("B", 5)},
+				sim.Monitor{Name: "DELL U2720Q", Workspaces: append(sim.Names("L", 5), sim.Names("R", 5)...)},
+			)
+			server.Focus("L1")
+			id := server.AddWindow("Ghostty", "L1")
+			server.SetMouse(tt.mouse)
+
+			st := store.New(filepath.Join(t.TempDir(), "state.json"))
+			if err := Execute(t.Context(), aerospace.NewClient(server), config.Default(), st, tt.num, 0); err != nil {
+				t.Fatalf("Execute(%d) error = %v", tt.num, err)
+			}
+			if got := server.WindowWorkspace(id); got != tt.expected {
+				t.Errorf("Execute(%d) moved the window to %q, expected %q", tt.num, got, tt.expected)
+			}
+			if got := server.FocusedWorkspace(); got != tt.expected {
+				t.Errorf("Execute(%d) focused %q, expected %q", tt.num, got, tt.expected)
+			}
+		})
+	}
+}
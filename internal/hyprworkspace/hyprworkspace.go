@@ -1,56 +1,62 @@
 package hyprworkspace
 
 import (
+	"context"
 	"fmt"
+	"os"
 
 	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+	"github.com/Xkonti/aeromanager/internal/topology"
 	"github.com/Xkonti/aeromanager/internal/workspacemap"
 )
 
-// Execute performs intelligent workspace switching based on cursor position
-func Execute(workspaceNum int) error {
+// Execute performs intelligent workspace switching based on cursor position.
+// A page of 0 uses the remembered page of the monitor with the mouse, any
+// other page is used and remembered as that monitor's current page.
+func Execute(ctx context.Context, client *aerospace.Client, cfg *config.Config, st *store.Store, workspaceNum int, page int) error {
 	// Validate workspace number (1-5 or 6-0, where 0 is treated as 10)
 	if workspaceNum < 0 || workspaceNum > 10 {
 		return fmt.Errorf("invalid workspace number: %d (must be 1-5 or 6-0)", workspaceNum)
 	}
-
-	// Get current workspace and monitor configuration
-	workspaces, monitors, err := aerospace.ListWorkspacesAndMonitors()
-	if err != nil {
-		return fmt.Errorf("failed to get workspace and monitor info: %w", err)
+	if page < 0 {
+		return fmt.Errorf("invalid page: %d", page)
 	}
 
-	// Get the monitor where the mouse cursor is
-	mouseMonitorID, err := aerospace.GetMouseMonitorID()
+	state, topo, err := topology.Snapshot(ctx, client, cfg, st, os.Stdout)
 	if err != nil {
-		return fmt.Errorf("failed to get mouse monitor: %w", err)
+		return err
 	}
+	mouseMonitorID := state.MouseMonitorID
+	mouseMonitor := st.PageKey(state.Monitors, mouseMonitorID)
 
-	// Determine which workspace to switch to based on monitor count and cursor position
-	targetWorkspace := workspacemap.MapWorkspaceNumber(workspaceNum, mouseMonitorID, monitors)
-
-	if len(monitors) > 3 {
-		return fmt.Errorf("unsupported monitor configuration: %d monitors", len(monitors))
+	// Determine which workspace to switch to based on the configured roles and cursor position
+	explicitPage := page != 0
+	if !explicitPage {
+		page = st.Page(mouseMonitor, workspacemap.Strategy(cfg, topo).Pages(mouseMonitorID, topo))
+	}
+	targetWorkspace, err := workspacemap.MapWorkspaceNumber(workspaceNum, page, mouseMonitorID, cfg, topo)
+	if err != nil {
+		return err
 	}
 
-	// Validate that the target workspace exists
-	if !workspaceExists(targetWorkspace, workspaces) {
-		return fmt.Errorf("workspace %s does not exist", targetWorkspace)
+	// Validate that the target workspace exists, absolute mapping may pick one on another monitor
+	ws := state.Workspace(targetWorkspace)
+	if ws == nil {
+		return fmt.Errorf("workspace %s does not exist: %w", targetWorkspace, aerospace.ErrUnknownWorkspace)
 	}
 
-	fmt.Printf("Switching to workspace %s on monitor %d\n", targetWorkspace, mouseMonitorID)
+	fmt.Printf("Switching to workspace %s on monitor %d\n", targetWorkspace, ws.MonitorID)
 
 	// Switch to the target workspace
-	return aerospace.SwitchWorkspace(targetWorkspace)
-}
-
+	if err := client.SwitchWorkspace(ctx, targetWorkspace); err != nil {
+		return err
+	}
 
-// workspaceExists checks if a workspace with the given name exists
-func workspaceExists(name string, workspaces []aerospace.Workspace) bool {
-	for _, ws := range workspaces {
-		if ws.Name == name {
-			return true
-		}
+	if explicitPage {
+		st.SetPage(mouseMonitor, page)
+		return st.Save()
 	}
-	return false
+	return nil
 }
@@ -0,0 +1,190 @@
+package hyprworkspace
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/aerospace/sim"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+)
+
+func TestExecute(t *testing.T) {
+	single := []sim.Monitor{
+		{Name: "Built-in Retina Display", Workspaces: append(append(sim.Names("L", 5), sim.Names("R", 5)...), sim.Names("B", 5)...)},
+	}
+	double := []sim.Monitor{
+		{Name: "Built-in Retina Display", IsMain: true, Workspaces: sim.Names("B", 5)},
+		{Name: "DELL U2720Q", Workspaces: append(sim.Names("L", 5), sim.Names("R", 5)...)},
+	}
+	docked := []sim.Monitor{
+		{Name: "DELL U2720Q", Workspaces: append(sim.Names("L", 5), sim.Names("R", 5)...)},
+		{Name: "LG HDR 4K", IsMain: true, Workspaces: sim.Names("B", 5)},
+	}
+	triple := []sim.Monitor{
+		{Name: "XZ272U P (2)", Workspaces: sim.Names("L", 5)},
+		{Name: "Built-in Retina Display", IsMain: true, Workspaces: sim.Names("B", 5)},
+		{Name: "XZ272U P (1)", Workspaces: sim.Names("R", 5)},
+	}
+
+	tests := []struct {
+		name     string
+		monitors []sim.Monitor
+		mouse    int
+		num      int
+		expected string // Focused workspace afterwards
+	}{
+		{name: "single lower keys", monitors: single, mouse: 1, num: 3, expected: "L3"},
+		{name: "single zero key", monitors: single, mouse: 1, num: 0, expected: "R5"},
+		{name: "double built-in wraps", monitors: double, mouse: 1, num: 7, expected: "B2"},
+		{name: "double external upper keys", monitors: double, mouse: 2, num: 7, expected: "R2"},
+		{name: "docked primary", monitors: docked, mouse: 2, num: 7, expected: "B2"},
+		{name: "docked other external", monitors: docked, mouse: 1, num: 7, expected: "R2"},
+		{name: "triple left", monitors: triple, mouse: 1, num: 2, expected: "L2"},
+		{name: "triple built-in", monitors: triple, mouse: 2, num: 7, expected: "B2"},
+		{name: "triple right", monitors: triple, mouse: 3, num: 1, expected: "R1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := sim.New(tt.monitors...)
+			server.SetMouse(tt.mouse)
+
+			st := store.New(filepath.Join(t.TempDir(), "state.json"))
+			if err := Execute(t.Context(), aerospace.NewClient(server), config.Default(), st, tt.num, 0); err != nil {
+				t.Fatalf("Execute(%d) error = %v", tt.num, err)
+			}
+			if got := server.FocusedWorkspace(); got != tt.expected {
+				t.Errorf("Execute(%d) focused %q, expected %q", tt.num, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExecuteFourMonitors(t *testing.T) {
+	tests := []struct {
+		name     string
+		mouse    int
+		expected string // Focused workspace afterwards, empty when an error is expected
+	}{
+		{name: "built-in", mouse: 2, expected: "B3"},
+		{name: "right external", mouse: 3, expected: "R3"},
+		{name: "spare monitor", mouse: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := sim.New(
+				sim.Monitor{Name: "DELL U2720Q", Workspaces: sim.Names("L", 5)},
+				sim.Monitor{Name: "Built-in Retina Display", IsMain: true, Workspaces: sim.Names("B", 5)},
+				sim.Monitor{Name: "DELL U2720Q (2)", Workspaces: sim.Names("R", 5)},
+				sim.Monitor{Name: "LG HDR 4K", Workspaces: []string{"X1"}},
+			)
+			server.SetMouse(tt.mouse)
+
+			st := store.New(filepath.Join(t.TempDir(), "state.json"))
+			err := Execute(t.Context(), aerospace.NewClient(server), config.Default(), st, 3, 0)
+			if tt.expected == "" {
+				if err == nil {
+					t.Errorf("Execute() on a monitor without roles error = nil, expected an error")
+				}
+				if len(server.Log()) != 0 {
+					t.Errorf("Execute() ran %v, expected no commands", server.Log())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := server.FocusedWorkspace(); got != tt.expected {
+				t.Errorf("Execute() focused %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExecuteAbsolute(t *testing.T) {
+	cfg := config.Default()
+	cfg.Mapping = config.MappingAbsolute
+	cfg.Keys = []string{"B1", "B2", "L1", "L2", "R1"}
+
+	server := sim.New(
+		sim.Monitor{Name: "Built-in Retina Display", IsMain: true, Workspaces: sim.Names("B", 5)},
+		sim.Monitor{Name: "DELL U2720Q", Workspaces: append(sim.Names("L", 5), sim.Names("R", 5)...)},
+	)
+	server.SetMouse(1)
+
+	// The key reaches the same workspace on the other monitor, focusing it there
+	st := store.New(filepath.Join(t.TempDir(), "state.json"))
+	if err := Execute(t.Context(), aerospace.NewClient(server), cfg, st, 4, 0); err != nil {
+		t.Fatalf("Execute(4) error = %v", err)
+	}
+	if got := server.FocusedWorkspace(); got != "L2" {
+		t.Errorf("Execute(4) focused %q, expected L2", got)
+	}
+	if got := server.VisibleOn(2); got != "L2" {
+		t.Errorf("Monitor 2 shows %q, expected L2", got)
+	}
+}
+
+func TestExecutePages(t *testing.T) {
+	cfg := config.Default()
+	cfg.Roles[1].Workspaces = sim.Names("B", 10)
+
+	server := sim.New(
+		sim.Monitor{Name: "Built-in Retina Display", IsMain: true, Workspaces: cfg.Roles[1].Workspaces},
+		sim.Monitor{Name: "DELL U2720Q", Workspaces: append(sim.Names("L", 5), sim.Names("R", 5)...)},
+	)
+	server.SetMouse(1)
+	st := store.New(filepath.Join(t.TempDir(), "state.json"))
+	client := aerospace.NewClient(server)
+
+	// An explicit page is used and remembered for the monitor
+	if err := Execute(t.Context(), client, cfg, st, 3, 2); err != nil {
+		t.Fatalf("Execute(3, page 2) error = %v", err)
+	}
+	if got := server.FocusedWorkspace(); got != "B8" {
+		t.Errorf("Execute(3, page 2) focused %q, expected B8", got)
+	}
+
+	if err := Execute(t.Context(), client, cfg, st, 0, 0); err != nil {
+		t.Fatalf("Execute(0) error = %v", err)
+	}
+	if got := server.FocusedWorkspace(); got != "B10" {
+		t.Errorf("Execute(0) on the remembered page focused %q, expected B10", got)
+	}
+
+	// Other monitors keep their own page
+	server.SetMouse(2)
+	if err := Execute(t.Context(), client, cfg, st, 7, 0); err != nil {
+		t.Fatalf("Execute(7) error = %v", err)
+	}
+	if got := server.FocusedWorkspace(); got != "R2" {
+		t.Errorf("Execute(7) on the external monitor focused %q, expected R2", got)
+	}
+}
+
+func TestExecuteStalePage(t *testing.T) {
+	server := sim.New(
+		sim.Monitor{Name: "Built-in Retina Display", IsMain: true, Workspaces: sim.Names("B", 5)},
+		sim.Monitor{Name: "DELL U2720Q", Workspaces: append(sim.Names("L", 5), sim.Names("R", 5)...)},
+	)
+	server.SetMouse(1)
+	client := aerospace.NewClient(server)
+	monitors, err := client.ListMonitors(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Remembered while the role had three pages of workspaces
+	st := store.New(filepath.Join(t.TempDir(), "state.json"))
+	st.SetPage(st.PageKey(monitors, 1), 3)
+
+	if err := Execute(t.Context(), client, config.Default(), st, 3, 0); err != nil {
+		t.Fatalf("Execute(3) error = %v", err)
+	}
+	if got := server.FocusedWorkspace(); got != "B3" {
+		t.Errorf("Execute(3) focused %q, expected B3 on page 1", got)
+	}
+}
@@ -0,0 +1,65 @@
+package label
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/store"
+)
+
+// List prints every connected monitor with its fingerprint and label
+func List(ctx context.Context, client *aerospace.Client, st *store.Store, w io.Writer) error {
+	monitors, err := client.ListMonitors(ctx)
+	if err != nil {
+		return err
+	}
+
+	fingerprints := aerospace.Fingerprints(monitors)
+	labels := st.Identify(monitors)
+	for _, mon := range monitors {
+		fmt.Fprintf(w, "Monitor %d %q: %s", mon.ID, mon.Name, fingerprints[mon.ID])
+		if label, ok := labels[mon.ID]; ok {
+			fmt.Fprintf(w, ", labeled %q", label)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// Execute labels the physical monitor currently connected as monitorID, so
+// config rules can target it by label across reconnects. An empty label
+// forgets the monitor.
+func Execute(ctx context.Context, client *aerospace.Client, st *store.Store, w io.Writer, monitorID int, label string) error {
+	monitors, err := client.ListMonitors(ctx)
+	if err != nil {
+		return err
+	}
+
+	fp, ok := aerospace.Fingerprints(monitors)[monitorID]
+	if !ok {
+		return fmt.Errorf("no monitor %d connected", monitorID)
+	}
+
+	// Forget whatever the monitor was identified as before, even by a looser match
+	if previous, ok := st.Identify(monitors)[monitorID]; ok && previous != label {
+		for _, l := range st.Labels {
+			if l.Label == previous {
+				st.SetLabel(l.Fingerprint, "")
+				break
+			}
+		}
+	}
+	st.SetLabel(fp, label)
+	if err := st.Save(); err != nil {
+		return err
+	}
+
+	if label == "" {
+		fmt.Fprintf(w, "Forgot the label of monitor %d (%s)\n", monitorID, fp)
+	} else {
+		fmt.Fprintf(w, "Labeled monitor %d (%s) %q\n", monitorID, fp, label)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package label
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/aerospace/sim"
+	"github.com/Xkonti/aeromanager/internal/store"
+)
+
+func TestExecute(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	st, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := sim.New(
+		sim.Monitor{Name: "XZ272U P (2)", AppKitID: 2},
+		sim.Monitor{Name: "Built-in Retina Display", IsMain: true, AppKitID: 1},
+		sim.Monitor{Name: "XZ272U P (1)", AppKitID: 3},
+	)
+	client := aerospace.NewClient(server)
+
+	var out strings.Builder
+	if err := Execute(t.Context(), client, st, &out, 1, "left"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if err := Execute(t.Context(), client, st, &out, 1, "desk"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if err := Execute(t.Context(), client, st, &out, 4, "missing"); err == nil {
+		t.Errorf("Execute() of a missing monitor succeeded, expected an error")
+	}
+
+	// The label was saved
+	st, err = store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := List(t.Context(), client, st, &out); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	expected := `Monitor 1 "XZ272U P (2)": XZ272U P, screen 2, position 1, labeled "desk"
+Monitor 2 "Built-in Retina Display": Built-in Retina Display, screen 1, position 1
+Monitor 3 "XZ272U P (1)": XZ272U P, screen 3, position 2
+`
+	if out.String() != expected {
+		t.Errorf("List() printed:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
@@ -0,0 +1,71 @@
+package page
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+	"github.com/Xkonti/aeromanager/internal/topology"
+	"github.com/Xkonti/aeromanager/internal/workspacemap"
+)
+
+// Execute changes the current page of the monitor with the mouse and switches
+// to the workspace bound to key 1 on it. The page is a number, "next" or "prev",
+// the latter two wrapping around.
+func Execute(ctx context.Context, client *aerospace.Client, cfg *config.Config, st *store.Store, target string) error {
+	state, topo, err := topology.Snapshot(ctx, client, cfg, st, os.Stdout)
+	if err != nil {
+		return err
+	}
+	mouseMonitorID := state.MouseMonitorID
+	mouseMonitor := st.PageKey(state.Monitors, mouseMonitorID)
+
+	strategy := workspacemap.Strategy(cfg, topo)
+	pages := strategy.Pages(mouseMonitorID, topo)
+	if pages == 0 {
+		return fmt.Errorf("monitor %d has no workspace pages", mouseMonitorID)
+	}
+
+	page, err := resolve(target, st.Page(mouseMonitor, pages), pages)
+	if err != nil {
+		return err
+	}
+
+	targetWorkspace, err := strategy.Map(1, page, mouseMonitorID, topo)
+	if err != nil {
+		return err
+	}
+	if state.Workspace(targetWorkspace) == nil {
+		return fmt.Errorf("workspace %s does not exist: %w", targetWorkspace, aerospace.ErrUnknownWorkspace)
+	}
+
+	fmt.Printf("Switching monitor %d to page %d of %d, workspace %s\n", mouseMonitorID, page, pages, targetWorkspace)
+
+	// Switch to the target workspace
+	if err := client.SwitchWorkspace(ctx, targetWorkspace); err != nil {
+		return err
+	}
+
+	st.SetPage(mouseMonitor, page)
+	return st.Save()
+}
+
+// resolve turns a page argument into a page number between 1 and pages
+func resolve(target string, current int, pages int) (int, error) {
+	switch target {
+	case "next":
+		return current%pages + 1, nil
+	case "prev":
+		return (current+pages-2)%pages + 1, nil
+	}
+
+	page, err := strconv.Atoi(target)
+	if err != nil || page < 1 || page > pages {
+		return 0, fmt.Errorf("invalid page: %s (must be 1-%d, next or prev)", target, pages)
+	}
+	return page, nil
+}
@@ -0,0 +1,84 @@
+package page
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/aerospace/sim"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		target   string
+		current  int
+		expected int // 0 when an error is expected
+	}{
+		{target: "next", current: 1, expected: 2},
+		{target: "next", current: 3, expected: 1},
+		{target: "prev", current: 1, expected: 3},
+		{target: "prev", current: 2, expected: 1},
+		{target: "2", current: 1, expected: 2},
+		{target: "4", current: 1},
+		{target: "first", current: 1},
+	}
+
+	for _, tt := range tests {
+		got, err := resolve(tt.target, tt.current, 3)
+		if tt.expected == 0 {
+			if err == nil {
+				t.Errorf("resolve(%s) = %d, expected an error", tt.target, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("resolve(%s) from page %d = %d, %v, expected %d", tt.target, tt.current, got, err, tt.expected)
+		}
+	}
+}
+
+func TestExecute(t *testing.T) {
+	cfg := config.Default()
+	cfg.Roles[1].Workspaces = sim.Names("B", 8)
+
+	server := sim.New(
+		sim.Monitor{Name: "Built-in Retina Display", IsMain: true, Workspaces: cfg.Roles[1].Workspaces},
+		sim.Monitor{Name: "DELL U2720Q", Workspaces: []string{"L1", "R1"}},
+	)
+	server.SetMouse(1)
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	st, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Execute(t.Context(), aerospace.NewClient(server), cfg, st, "next"); err != nil {
+		t.Fatalf("Execute(next) error = %v", err)
+	}
+	if got := server.FocusedWorkspace(); got != "B6" {
+		t.Errorf("Execute(next) focused %q, expected B6", got)
+	}
+
+	// The page survives into the next invocation
+	st, err = store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	monitors, err := aerospace.NewClient(server).ListMonitors(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page := st.Page(st.PageKey(monitors, 1), 2); page != 2 {
+		t.Errorf("Page() = %d, expected 2", page)
+	}
+
+	if err := Execute(t.Context(), aerospace.NewClient(server), cfg, st, "next"); err != nil {
+		t.Fatalf("Execute(next) error = %v", err)
+	}
+	if got := server.FocusedWorkspace(); got != "B1" {
+		t.Errorf("Execute(next) on the last page focused %q, expected B1", got)
+	}
+}
@@ -0,0 +1,137 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+	"github.com/Xkonti/aeromanager/internal/topology"
+)
+
+// Capture writes a profile named name to the config file at path that
+// reproduces the current arrangement: every role goes to the monitor holding
+// most of its workspaces, and the visible workspaces are focused again, the
+// focused one last. Monitors are picked by label when they have one, else by
+// name, adding their position when several share it.
+func Capture(ctx context.Context, client *aerospace.Client, cfg *config.Config, st *store.Store, w io.Writer, path string, name string) error {
+	workspaces, monitors, err := client.ListWorkspacesAndMonitors(ctx)
+	if err != nil {
+		return err
+	}
+
+	labels := st.Identify(monitors)
+	ordered, err := topology.Arrange(cfg, monitors, labels)
+	if err != nil {
+		return err
+	}
+	names, err := topology.MonitorSet(cfg, monitors, labels)
+	if err != nil {
+		return err
+	}
+
+	where := make(map[string]int, len(workspaces))
+	for _, ws := range workspaces {
+		where[ws.Name] = ws.MonitorID
+	}
+
+	// Each role goes where most of its workspaces are, the first one breaking ties
+	rolesOn := make(map[int][]string)
+	for _, role := range cfg.Roles {
+		counts := make(map[int]int)
+		best, total := 0, 0
+		for _, ws := range role.Workspaces {
+			id, ok := where[ws]
+			if !ok || !isPermanent(ordered, id) {
+				continue
+			}
+			counts[id]++
+			total++
+			if best == 0 || counts[id] > counts[best] {
+				best = id
+			}
+		}
+		if best == 0 {
+			fmt.Fprintf(w, "Role %s has no workspace on a permanent monitor, left out\n", role.Name)
+			continue
+		}
+		if counts[best] < total {
+			fmt.Fprintf(w, "Role %s is split across monitors, assigning it to monitor %d\n", role.Name, best)
+		}
+		rolesOn[best] = append(rolesOn[best], role.Name)
+	}
+
+	p := config.Profile{Name: name, Monitors: names}
+	for i, mon := range ordered {
+		if len(rolesOn[mon.ID]) == 0 {
+			continue
+		}
+		p.Assign = append(p.Assign, config.Assignment{
+			Monitor: identify(mon, i+1, ordered, labels),
+			Roles:   rolesOn[mon.ID],
+		})
+	}
+
+	focused := ""
+	for _, ws := range workspaces {
+		if !ws.IsVisible || cfg.RoleOf(ws.Name) == nil || !isPermanent(ordered, ws.MonitorID) {
+			continue
+		}
+		if ws.IsFocused {
+			focused = ws.Name
+			continue
+		}
+		p.Focus = append(p.Focus, ws.Name)
+	}
+	if focused != "" {
+		p.Focus = append(p.Focus, focused)
+	}
+
+	if err := config.SaveProfile(path, p); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Saved profile %s for %s to %s\n", name, strings.Join(names, ", "), path)
+	for _, a := range p.Assign {
+		fmt.Fprintf(w, "  %s: %s\n", a.Monitor, strings.Join(a.Roles, ", "))
+	}
+	if earlier := cfg.ProfileFor(names); earlier != nil && earlier.Name != name && cfg.Profile == "" {
+		fmt.Fprintf(w, "Warning: profile %s also matches these monitors and comes first\n", earlier.Name)
+	}
+	return nil
+}
+
+// identify returns a rule picking the monitor at the 1-based position
+func identify(mon aerospace.Monitor, position int, ordered []aerospace.Monitor, labels map[int]string) config.Match {
+	if label, ok := labels[mon.ID]; ok {
+		return config.Match{Label: label}
+	}
+
+	base := aerospace.BaseName(mon.Name)
+	rule := config.Match{Name: `^` + regexp.QuoteMeta(base) + `( \(\d+\))?$`}
+	shared := 0
+	for _, other := range ordered {
+		if aerospace.BaseName(other.Name) == base {
+			shared++
+		}
+	}
+	if shared > 1 {
+		rule.Position = strconv.Itoa(position)
+	}
+	return rule
+}
+
+// isPermanent reports whether the monitor is among the arranged permanent ones
+func isPermanent(ordered []aerospace.Monitor, monitorID int) bool {
+	for _, mon := range ordered {
+		if mon.ID == monitorID {
+			return true
+		}
+	}
+	return false
+}
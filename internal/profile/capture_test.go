@@ -0,0 +1,87 @@
+package profile
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/aerospace/sim"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/rearrange"
+	"github.com/Xkonti/aeromanager/internal/store"
+)
+
+func TestCapture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	monitors := []sim.Monitor{
+		{Name: "DELL U2720Q (1)", AppKitID: 2},
+		{Name: "Built-in Retina Display", IsMain: true, AppKitID: 1},
+		{Name: "DELL U2720Q (2)", AppKitID: 3},
+		{Name: "Sidecar Display (AirPlay)", AppKitID: 4},
+	}
+	st := &store.Store{}
+	st.SetLabel(aerospace.Fingerprint{Name: "DELL U2720Q", AppKitID: 3, Position: 2}, "right dell")
+
+	// Arranged by hand, with L5 left behind on the left monitor
+	monitors[0].Workspaces = append(sim.Names("R", 5), "L5")
+	monitors[1].Workspaces = sim.Names("B", 5)
+	monitors[2].Workspaces = sim.Names("L", 5)[:4]
+	monitors[3].Workspaces = []string{"S1"}
+	server := sim.New(monitors...)
+
+	var out strings.Builder
+	if err := Capture(t.Context(), aerospace.NewClient(server), config.Default(), st, &out, path, "desk"); err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Role L is split across monitors, assigning it to monitor 3") {
+		t.Errorf("Capture() printed:\n%s\nexpected it to mention the split role L", out.String())
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	p := cfg.FindProfile("desk")
+	if p == nil {
+		t.Fatalf("Load() found no profile desk")
+	}
+	if !p.Matches([]string{"Built-in Retina Display", "DELL U2720Q", "DELL U2720Q"}) {
+		t.Errorf("Monitors = %v, expected the built-in display and two Dells", p.Monitors)
+	}
+	expected := []string{
+		`name=~"^DELL U2720Q( \\(\\d+\\))?$", position=1: R`,
+		`name=~"^Built-in Retina Display( \\(\\d+\\))?$": B`,
+		`label="right dell": L`,
+	}
+	if len(p.Assign) != len(expected) {
+		t.Fatalf("Assign = %v, expected %d assignments", p.Assign, len(expected))
+	}
+	for i, a := range p.Assign {
+		if got := a.Monitor.String() + ": " + strings.Join(a.Roles, ", "); got != expected[i] {
+			t.Errorf("Assign[%d] = %s, expected %s", i, got, expected[i])
+		}
+	}
+	if got := strings.Join(p.Focus, ","); got != "R1,L1,B1" {
+		t.Errorf("Focus = %s, expected R1,L1,B1 with the focused B1 last", got)
+	}
+
+	// After a replug everything piles up on the main display, rearranging restores the capture
+	for i := range monitors {
+		monitors[i].Workspaces = nil
+	}
+	monitors[1].Workspaces = append(append(sim.Names("L", 5), sim.Names("B", 5)...), sim.Names("R", 5)...)
+	server = sim.New(monitors...)
+	if err := rearrange.Execute(t.Context(), aerospace.NewClient(server), cfg, st, io.Discard); err != nil {
+		t.Fatalf("rearrange.Execute() error = %v", err)
+	}
+	for id, role := range map[int]string{1: "R", 2: "B", 3: "L"} {
+		if got := server.MonitorOf(role + "1"); got != id {
+			t.Errorf("%s1 is on monitor %d, expected %d", role, got, id)
+		}
+	}
+	if got := server.FocusedWorkspace(); got != "B1" {
+		t.Errorf("Focused %q, expected B1", got)
+	}
+}
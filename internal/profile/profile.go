@@ -0,0 +1,77 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+	"github.com/Xkonti/aeromanager/internal/topology"
+)
+
+// List prints the connected monitor set and every profile, marking the one
+// in use
+func List(ctx context.Context, client *aerospace.Client, cfg *config.Config, st *store.Store, w io.Writer) error {
+	monitors, err := client.ListMonitors(ctx)
+	if err != nil {
+		return err
+	}
+	labels := st.Identify(monitors)
+	names, err := topology.MonitorSet(cfg, monitors, labels)
+	if err != nil {
+		return err
+	}
+	topo, err := topology.Resolve(cfg, monitors, labels)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Monitors: %s\n", strings.Join(names, ", "))
+	if topo.Warning != "" {
+		fmt.Fprintf(w, "Warning: %s\n", topo.Warning)
+	}
+	active := topo.Profile
+	if len(cfg.Profiles) > 0 {
+		fmt.Fprintln(w, "Profiles:")
+	}
+	for _, p := range cfg.Profiles {
+		marker := ""
+		if active != nil && p.Name == active.Name {
+			marker = " (active)"
+			if cfg.Profile != "" && topo.Warning == "" {
+				marker = " (active, forced)"
+			}
+		}
+		fmt.Fprintf(w, "  %s: %s%s\n", p.Name, strings.Join(p.Monitors, ", "), marker)
+	}
+	if active == nil {
+		fmt.Fprintf(w, "No profile matches, using the layout for %d monitors\n", len(names))
+	}
+	return nil
+}
+
+// Use forces a profile whatever monitors are connected, until Auto is used
+func Use(cfg *config.Config, st *store.Store, w io.Writer, name string) error {
+	if cfg.FindProfile(name) == nil {
+		return fmt.Errorf("unknown profile %s", name)
+	}
+	st.Profile = name
+	if err := st.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Using profile %s until aeromanager profile auto, run aeromanager rearrange to apply it\n", name)
+	return nil
+}
+
+// Auto goes back to picking the profile by the connected monitors
+func Auto(st *store.Store, w io.Writer) error {
+	st.Profile = ""
+	if err := st.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "Picking profiles by the connected monitors, run aeromanager rearrange to apply it")
+	return nil
+}
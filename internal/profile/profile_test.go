@@ -0,0 +1,103 @@
+package profile
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/aerospace/sim"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+)
+
+func testConfig() *config.Config {
+	cfg := config.Default()
+	cfg.Profiles = []config.Profile{
+		{
+			Name:     "home",
+			Monitors: []string{"Built-in Retina Display", "LG HDR WQHD"},
+			Assign:   []config.Assignment{{Monitor: config.MatchExternal, Roles: []string{"L", "R", "B"}}},
+		},
+		{
+			Name:     "office",
+			Monitors: []string{"Built-in Retina Display", "DELL U2720Q", "DELL U2720Q"},
+			Assign:   []config.Assignment{{Monitor: config.MatchAny, Roles: []string{"L", "R", "B"}}},
+		},
+	}
+	return cfg
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		name     string
+		forced   string
+		expected string
+	}{
+		{
+			name: "picked by monitors",
+			expected: `Monitors: DELL U2720Q, Built-in Retina Display, DELL U2720Q
+Profiles:
+  home: Built-in Retina Display, LG HDR WQHD
+  office: Built-in Retina Display, DELL U2720Q, DELL U2720Q (active)
+`,
+		},
+		{
+			name:   "forced",
+			forced: "home",
+			expected: `Monitors: DELL U2720Q, Built-in Retina Display, DELL U2720Q
+Profiles:
+  home: Built-in Retina Display, LG HDR WQHD (active, forced)
+  office: Built-in Retina Display, DELL U2720Q, DELL U2720Q
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Profile = tt.forced
+			server := sim.New(
+				sim.Monitor{Name: "DELL U2720Q (1)"},
+				sim.Monitor{Name: "Built-in Retina Display", IsMain: true},
+				sim.Monitor{Name: "DELL U2720Q (2)"},
+				sim.Monitor{Name: "Sidecar Display (AirPlay)"},
+			)
+
+			var out strings.Builder
+			if err := List(t.Context(), aerospace.NewClient(server), cfg, &store.Store{}, &out); err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("List() printed:\n%s\nexpected:\n%s", out.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestUseAndAuto(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	st, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig()
+
+	var out strings.Builder
+	if err := Use(cfg, st, &out, "garage"); err == nil {
+		t.Errorf("Use() of an unknown profile succeeded, expected an error")
+	}
+	if err := Use(cfg, st, &out, "home"); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+	if st, err = store.Open(path); err != nil || st.Profile != "home" {
+		t.Fatalf("Forced profile = %q, %v, expected home", st.Profile, err)
+	}
+
+	if err := Auto(st, &out); err != nil {
+		t.Fatalf("Auto() error = %v", err)
+	}
+	if st, err = store.Open(path); err != nil || st.Profile != "" {
+		t.Errorf("Forced profile = %q, %v, expected none", st.Profile, err)
+	}
+}
@@ -0,0 +1,186 @@
+package rearrange
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/topology"
+)
+
+// Plan is everything rearranging does, decided from a single snapshot
+// without touching Aerospace. Steps run in order: creates, then moves, then
+// focus changes.
+type Plan struct {
+	Topology *topology.Topology `json:"-"`
+	State    *aerospace.State   `json:"-"` // Snapshot the plan was made from
+
+	Profile  string        `json:"profile,omitempty"` // Profile the layout comes from, empty when picked by monitor count
+	Monitors []MonitorPlan `json:"monitors"`
+	Creates  []Create      `json:"creates"`
+	Moves    []Move        `json:"moves"`
+	Focus    []string      `json:"focus"`    // Workspaces focused in order, the last one keeps focus
+	InPlace  []string      `json:"in_place"` // Role workspaces already on their monitor
+	Orphans  []Orphan      `json:"orphans"`  // Workspaces owned by no role, left where they are
+}
+
+// MonitorPlan is what a monitor hosts once rearranged
+type MonitorPlan struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	Roles     []string `json:"roles"`
+	Transient string   `json:"transient,omitempty"` // Dedicated workspace of a transient monitor
+}
+
+// Create makes a missing transient workspace on its monitor. Aerospace
+// creates a workspace on the focused monitor, so Via, the workspace visible
+// there, is focused first.
+type Create struct {
+	Workspace string `json:"workspace"`
+	Monitor   int    `json:"monitor"`
+	Via       string `json:"via"`
+}
+
+// Move sends a workspace from the monitor it is on to the one hosting its role
+type Move struct {
+	Workspace string `json:"workspace"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+}
+
+// Orphan is a workspace owned by no role and the monitor it stays on
+type Orphan struct {
+	Workspace string `json:"workspace"`
+	Monitor   int    `json:"monitor"`
+}
+
+// NewPlan decides how to rearrange the snapshot's workspaces for the config
+// layout of its monitors. labels names the physical monitors, it may be nil.
+func NewPlan(cfg *config.Config, state *aerospace.State, labels map[int]string) (*Plan, error) {
+	topo, err := topology.Resolve(cfg, state.Monitors, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plan{
+		Topology: topo,
+		State:    state,
+		Monitors: []MonitorPlan{},
+		Creates:  []Create{},
+		Moves:    []Move{},
+		Focus:    append([]string{}, topo.Layout.Focus...),
+		InPlace:  []string{},
+		Orphans:  []Orphan{},
+	}
+	if topo.Profile != nil {
+		p.Profile = topo.Profile.Name
+	}
+
+	for _, a := range topo.Assignments {
+		roles := make([]string, 0, len(a.Roles))
+		for _, role := range a.Roles {
+			roles = append(roles, role.Name)
+		}
+		p.Monitors = append(p.Monitors, MonitorPlan{ID: a.Monitor.ID, Name: a.Monitor.Name, Roles: roles})
+	}
+	for _, mon := range topo.Spare {
+		p.Monitors = append(p.Monitors, MonitorPlan{ID: mon.ID, Name: mon.Name, Roles: []string{}})
+	}
+	var transientFocus []string
+	for _, tr := range topo.Transient {
+		p.Monitors = append(p.Monitors, MonitorPlan{ID: tr.Monitor.ID, Name: tr.Monitor.Name, Roles: []string{}, Transient: tr.Workspace})
+
+		// An existing workspace moved onto its monitor would stay hidden
+		// behind the one shown there, so it is focused like a created one
+		if ws := state.Workspace(tr.Workspace); ws != nil {
+			if ws.MonitorID != tr.Monitor.ID || !ws.IsVisible {
+				transientFocus = append(transientFocus, tr.Workspace)
+			}
+			continue
+		}
+		visible := state.VisibleWorkspaceOn(tr.Monitor.ID)
+		if visible == "" {
+			continue
+		}
+		p.Creates = append(p.Creates, Create{Workspace: tr.Workspace, Monitor: tr.Monitor.ID, Via: visible})
+	}
+	// Before the layout's focus, which decides the workspace keeping focus
+	p.Focus = append(transientFocus, p.Focus...)
+
+	for _, ws := range state.Workspaces {
+		target, ok := topo.MonitorFor(ws.Name)
+		switch {
+		case !ok:
+			p.Orphans = append(p.Orphans, Orphan{Workspace: ws.Name, Monitor: ws.MonitorID})
+		case ws.MonitorID == target:
+			p.InPlace = append(p.InPlace, ws.Name)
+		default:
+			p.Moves = append(p.Moves, Move{Workspace: ws.Name, From: ws.MonitorID, To: target})
+		}
+	}
+
+	return p, nil
+}
+
+// PrintSummary prints the monitors found and the roles each one gets
+func (p *Plan) PrintSummary(w io.Writer) {
+	fmt.Fprintf(w, "Found %d monitors and %d workspaces\n", len(p.State.Monitors), len(p.State.Workspaces))
+	if p.Topology.Warning != "" {
+		fmt.Fprintf(w, "Warning: %s\n", p.Topology.Warning)
+	}
+	if p.Profile != "" {
+		fmt.Fprintf(w, "Using profile %s\n", p.Profile)
+	}
+	if p.Topology.Docked {
+		fmt.Fprintf(w, "No built-in display, monitor %d stands in for it\n", p.Topology.Primary)
+	}
+	for _, mon := range p.Monitors {
+		switch {
+		case mon.Transient != "":
+			fmt.Fprintf(w, "Monitor %d (%s): transient, workspace %s\n", mon.ID, mon.Name, mon.Transient)
+		case len(mon.Roles) == 0:
+			fmt.Fprintf(w, "Monitor %d (%s): no roles, left as is\n", mon.ID, mon.Name)
+		default:
+			fmt.Fprintf(w, "Monitor %d (%s): %s\n", mon.ID, mon.Name, strings.Join(mon.Roles, ", "))
+		}
+	}
+}
+
+// Print prints the summary followed by every step of the plan
+func (p *Plan) Print(w io.Writer) {
+	p.PrintSummary(w)
+
+	fmt.Fprintln(w, "Plan:")
+	for _, c := range p.Creates {
+		fmt.Fprintf(w, "  Create workspace %s on monitor %d, focusing %s first\n", c.Workspace, c.Monitor, c.Via)
+	}
+	for _, m := range p.Moves {
+		fmt.Fprintf(w, "  Move workspace %s from monitor %d to monitor %d\n", m.Workspace, m.From, m.To)
+	}
+	for _, ws := range p.Focus {
+		fmt.Fprintf(w, "  Focus workspace %s\n", ws)
+	}
+	if len(p.Creates)+len(p.Moves)+len(p.Focus) == 0 {
+		fmt.Fprintln(w, "  Nothing to do")
+	}
+
+	if len(p.InPlace) > 0 {
+		fmt.Fprintf(w, "Already in place: %s\n", strings.Join(p.InPlace, ", "))
+	}
+	for _, o := range p.Orphans {
+		fmt.Fprintf(w, "Workspace %s is owned by no role, left on monitor %d\n", o.Workspace, o.Monitor)
+	}
+}
+
+// PrintJSON prints the plan as indented JSON
+func (p *Plan) PrintJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
@@ -0,0 +1,139 @@
+package rearrange
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/aerospace/sim"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+)
+
+// replugged is a built-in display holding the L workspaces after a replug,
+// a Sidecar without its transient workspace and a Dell
+func replugged() *sim.Server {
+	return sim.New(
+		sim.Monitor{Name: "Built-in Retina Display", IsMain: true, Workspaces: append(append(sim.Names("B", 5), sim.Names("L", 5)...), "scratch")},
+		sim.Monitor{Name: "Sidecar Display (AirPlay)", Workspaces: []string{"S1"}},
+		sim.Monitor{Name: "DELL U2720Q", Workspaces: sim.Names("R", 5)},
+	)
+}
+
+func TestNewPlan(t *testing.T) {
+	cfg := config.Default()
+	state, err := aerospace.NewClient(replugged()).Snapshot(t.Context(), aerospace.SnapshotOptions{})
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	plan, err := NewPlan(cfg, state, nil)
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		got      any
+		expected any
+	}{
+		{name: "monitors", got: plan.Monitors, expected: []MonitorPlan{
+			{ID: 1, Name: "Built-in Retina Display", Roles: []string{"B"}},
+			{ID: 3, Name: "DELL U2720Q", Roles: []string{"L", "R"}},
+			{ID: 2, Name: "Sidecar Display (AirPlay)", Roles: []string{}, Transient: "T"},
+		}},
+		{name: "creates", got: plan.Creates, expected: []Create{{Workspace: "T", Monitor: 2, Via: "S1"}}},
+		{name: "moves", got: plan.Moves, expected: []Move{
+			{Workspace: "L1", From: 1, To: 3},
+			{Workspace: "L2", From: 1, To: 3},
+			{Workspace: "L3", From: 1, To: 3},
+			{Workspace: "L4", From: 1, To: 3},
+			{Workspace: "L5", From: 1, To: 3},
+		}},
+		{name: "focus", got: plan.Focus, expected: cfg.LayoutFor(2).Focus},
+		{name: "in place", got: plan.InPlace, expected: append(sim.Names("B", 5), sim.Names("R", 5)...)},
+		{name: "orphans", got: plan.Orphans, expected: []Orphan{{Workspace: "scratch", Monitor: 1}, {Workspace: "S1", Monitor: 2}}},
+	}
+
+	for _, tt := range tests {
+		if fmt.Sprint(tt.got) != fmt.Sprint(tt.expected) {
+			t.Errorf("Plan %s = %v, expected %v", tt.name, tt.got, tt.expected)
+		}
+	}
+}
+
+func TestNewPlanMovesTransientWorkspace(t *testing.T) {
+	// T was left on the built-in display when the Sidecar was last disconnected
+	server := sim.New(
+		sim.Monitor{Name: "Built-in Retina Display", IsMain: true, Workspaces: append(sim.Names("B", 5), "T")},
+		sim.Monitor{Name: "Sidecar Display (AirPlay)", Workspaces: []string{"S1"}},
+	)
+	client := aerospace.NewClient(server)
+	state, err := client.Snapshot(t.Context(), aerospace.SnapshotOptions{})
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	plan, err := NewPlan(config.Default(), state, nil)
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	if len(plan.Creates) != 0 || fmt.Sprint(plan.Moves) != fmt.Sprint([]Move{{Workspace: "T", From: 1, To: 2}}) {
+		t.Errorf("Plan creates %v and moves %v, expected only T moved to monitor 2", plan.Creates, plan.Moves)
+	}
+	if len(plan.Focus) == 0 || plan.Focus[0] != "T" {
+		t.Errorf("Plan focus = %v, expected T first", plan.Focus)
+	}
+
+	if err := Apply(t.Context(), client, plan, io.Discard); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if got := server.VisibleOn(2); got != "T" {
+		t.Errorf("Monitor 2 shows %q after Apply(), expected T", got)
+	}
+	if got := server.FocusedWorkspace(); got != "B1" {
+		t.Errorf("Focused workspace = %q after Apply(), expected the layout's B1", got)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	server := replugged()
+	client := aerospace.NewClient(server)
+
+	var out strings.Builder
+	if err := DryRun(t.Context(), client, config.Default(), &store.Store{}, &out, false); err != nil {
+		t.Fatalf("DryRun() error = %v", err)
+	}
+	for _, expected := range []string{
+		"Monitor 2 (Sidecar Display (AirPlay)): transient, workspace T\n",
+		"  Create workspace T on monitor 2, focusing S1 first\n",
+		"  Move workspace L3 from monitor 1 to monitor 3\n",
+		"Workspace scratch is owned by no role, left on monitor 1\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("DryRun() printed:\n%s\nexpected it to contain %q", out.String(), expected)
+		}
+	}
+
+	out.Reset()
+	if err := DryRun(t.Context(), client, config.Default(), &store.Store{}, &out, true); err != nil {
+		t.Fatalf("DryRun() error = %v", err)
+	}
+	var plan Plan
+	if err := json.Unmarshal([]byte(out.String()), &plan); err != nil {
+		t.Fatalf("DryRun() printed invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(plan.Moves) != 5 || plan.Moves[0] != (Move{Workspace: "L1", From: 1, To: 3}) {
+		t.Errorf("JSON moves = %v, expected L1-L5 to monitor 3", plan.Moves)
+	}
+
+	if log := server.Log(); len(log) != 0 {
+		t.Errorf("DryRun() changed Aerospace: %v", log)
+	}
+	if got := server.WorkspacesOn(3); !sameSet(got, sim.Names("R", 5)) {
+		t.Errorf("Monitor 3 has %v after a dry run, expected only the R workspaces", got)
+	}
+}
@@ -1,148 +1,75 @@
 package rearrange
 
 import (
+	"context"
 	"fmt"
-	"strings"
+	"io"
 
 	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
 )
 
-// Execute performs the workspace rearrangement based on monitor setup
-func Execute() error {
-	// Get current workspace and monitor configuration
-	workspaces, monitors, err := aerospace.ListWorkspacesAndMonitors()
+// Execute moves every workspace owned by a role onto the monitor hosting that
+// role, as declared by the config layout for the current monitor count, and
+// gives every transient monitor its dedicated workspace, printing each step to w
+func Execute(ctx context.Context, client *aerospace.Client, cfg *config.Config, st *store.Store, w io.Writer) error {
+	plan, err := snapshotPlan(ctx, client, cfg, st)
 	if err != nil {
-		return fmt.Errorf("failed to get workspace and monitor info: %w", err)
-	}
-
-	fmt.Printf("Found %d monitors and %d workspaces\n", len(monitors), len(workspaces))
-
-	// Determine arrangement based on monitor count
-	switch len(monitors) {
-	case 1:
-		// Single monitor - no rearrangement needed
-		aerospace.SwitchWorkspace("B1")
-		return nil
-
-	case 2:
-		return rearrangeTwoMonitors(workspaces, monitors)
-
-	case 3:
-		return rearrangeThreeMonitors(workspaces, monitors)
-
-	default:
-		return fmt.Errorf("unsupported monitor configuration: %d monitors", len(monitors))
+		return err
 	}
+	plan.PrintSummary(w)
+	return Apply(ctx, client, plan, w)
 }
 
-// rearrangeTwoMonitors handles the 2-monitor setup:
-// Built-in monitor gets B1-B5 workspaces, the other gets L1-L5 and R1-R5
-func rearrangeTwoMonitors(workspaces []aerospace.Workspace, monitors []aerospace.Monitor) error {
-	// Find the built-in monitor
-	var builtInID int
-	var externalID int
-
-	for _, mon := range monitors {
-		if strings.Contains(mon.Name, "Built-in") {
-			builtInID = mon.ID
-		} else {
-			externalID = mon.ID
-		}
+// DryRun prints what Execute would do, as text or as JSON, without changing
+// anything in Aerospace
+func DryRun(ctx context.Context, client *aerospace.Client, cfg *config.Config, st *store.Store, w io.Writer, asJSON bool) error {
+	plan, err := snapshotPlan(ctx, client, cfg, st)
+	if err != nil {
+		return err
 	}
-
-	if builtInID == 0 || externalID == 0 {
-		return fmt.Errorf("could not identify built-in and external monitors")
+	if asJSON {
+		return plan.PrintJSON(w)
 	}
-
-	fmt.Printf("Built-in monitor: %d, External monitor: %d\n", builtInID, externalID)
-
-	// Move workspaces to appropriate monitors
-	for _, ws := range workspaces {
-		var targetMonitor int
-
-		// B1-B5 go to built-in, L1-L5 and R1-R5 go to external
-		if (ws.Name >= "B1" && ws.Name <= "B5") {
-			targetMonitor = builtInID
-		} else if (ws.Name >= "L1" && ws.Name <= "L5") || (ws.Name >= "R1" && ws.Name <= "R5") {
-			targetMonitor = externalID
-		} else {
-			// Skip unexpected workspace names
-			continue
-		}
-
-		// Only move if not already on the correct monitor
-		if ws.MonitorID != targetMonitor {
-			fmt.Printf("Moving workspace %s to monitor %d\n", ws.Name, targetMonitor)
-			if err := aerospace.MoveWorkspaceToMonitor(ws.Name, targetMonitor); err != nil {
-				return fmt.Errorf("failed to move workspace %s: %w", ws.Name, err)
-			}
-		}
-	}
-
-	aerospace.SwitchWorkspace("B1")
-	aerospace.SwitchWorkspace("L1")
-
+	plan.Print(w)
 	return nil
 }
 
-// rearrangeThreeMonitors handles the 3-monitor setup:
-// Built-in gets B1-B5, left external (smaller ID) gets L1-L5, right external gets R1-R5
-func rearrangeThreeMonitors(workspaces []aerospace.Workspace, monitors []aerospace.Monitor) error {
-	// Find built-in and external monitors
-	var builtInID int
-	var externalIDs []int
-
-	for _, mon := range monitors {
-		if strings.Contains(mon.Name, "Built-in") {
-			builtInID = mon.ID
-		} else {
-			externalIDs = append(externalIDs, mon.ID)
-		}
-	}
-
-	if builtInID == 0 || len(externalIDs) != 2 {
-		return fmt.Errorf("could not identify monitors properly for 3-monitor setup")
+// snapshotPlan plans rearranging from a consistent snapshot of workspaces and monitors
+func snapshotPlan(ctx context.Context, client *aerospace.Client, cfg *config.Config, st *store.Store) (*Plan, error) {
+	state, err := client.Snapshot(ctx, aerospace.SnapshotOptions{})
+	if err != nil {
+		return nil, err
 	}
+	return NewPlan(cfg, state, st.Identify(state.Monitors))
+}
 
-	// The external monitors are already sorted by ID (left to right)
-	// Smaller ID is left, larger ID is right
-	leftExternalID := externalIDs[0]
-	rightExternalID := externalIDs[1]
-	if externalIDs[1] < externalIDs[0] {
-		leftExternalID = externalIDs[1]
-		rightExternalID = externalIDs[0]
+// Apply carries out the plan's steps in order, printing each one to w
+func Apply(ctx context.Context, client *aerospace.Client, plan *Plan, w io.Writer) error {
+	for _, c := range plan.Creates {
+		fmt.Fprintf(w, "Creating workspace %s on monitor %d\n", c.Workspace, c.Monitor)
+		for _, ws := range []string{c.Via, c.Workspace} {
+			if err := client.SwitchWorkspace(ctx, ws); err != nil {
+				return fmt.Errorf("failed to create workspace %s: %w", c.Workspace, err)
+			}
+		}
 	}
 
-	fmt.Printf("Built-in: %d, Left external: %d, Right external: %d\n", builtInID, leftExternalID, rightExternalID)
-
-	// Move workspaces to appropriate monitors
-	for _, ws := range workspaces {
-		var targetMonitor int
-
-		// B1-B5 go to built-in, L1-L5 go to left external, R1-R5 go to right external
-		if ws.Name >= "B1" && ws.Name <= "B5" {
-			targetMonitor = builtInID
-		} else if ws.Name >= "L1" && ws.Name <= "L5" {
-			targetMonitor = leftExternalID
-		} else if ws.Name >= "R1" && ws.Name <= "R5" {
-			targetMonitor = rightExternalID
-		} else {
-			// Skip unexpected workspace names
-			continue
+	for _, m := range plan.Moves {
+		fmt.Fprintf(w, "Moving workspace %s to monitor %d\n", m.Workspace, m.To)
+		seen := len(client.Warnings())
+		if err := client.MoveWorkspaceToMonitor(ctx, m.Workspace, m.To); err != nil {
+			return fmt.Errorf("failed to move workspace %s: %w", m.Workspace, err)
 		}
-
-		// Only move if not already on the correct monitor
-		if ws.MonitorID != targetMonitor {
-			fmt.Printf("Moving workspace %s to monitor %d\n", ws.Name, targetMonitor)
-			if err := aerospace.MoveWorkspaceToMonitor(ws.Name, targetMonitor); err != nil {
-				return fmt.Errorf("failed to move workspace %s: %w", ws.Name, err)
-			}
+		for _, warning := range client.Warnings()[seen:] {
+			fmt.Fprintf(w, "Warning: %v\n", warning)
 		}
 	}
 
-	aerospace.SwitchWorkspace("B1")
-	aerospace.SwitchWorkspace("L1")
-	aerospace.SwitchWorkspace("R1")
+	for _, ws := range plan.Focus {
+		client.SwitchWorkspace(ctx, ws)
+	}
 
 	return nil
 }
@@ -0,0 +1,204 @@
+package rearrange
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/aerospace/sim"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+)
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *config.Config // config.Default() when nil
+		monitors []sim.Monitor
+		expected [][]string // Workspaces on each monitor after rearranging
+		visible  []string   // Visible workspace on each monitor after rearranging
+		wantErr  bool
+	}{
+		{
+			name: "single built-in",
+			monitors: []sim.Monitor{
+				{Name: "Built-in Retina Display", Workspaces: append(append(sim.Names("L", 5), sim.Names("B", 5)...), sim.Names("R", 5)...)},
+			},
+			expected: [][]string{append(append(sim.Names("L", 5), sim.Names("B", 5)...), sim.Names("R", 5)...)},
+			visible:  []string{"B1"},
+		},
+		{
+			name: "built-in and external after replug",
+			monitors: []sim.Monitor{
+				{Name: "Built-in Retina Display", IsMain: true, Workspaces: append(sim.Names("L", 5), "B1", "B2")},
+				{Name: "DELL U2720Q", Workspaces: append([]string{"B3", "B4", "B5"}, sim.Names("R", 5)...)},
+			},
+			expected: [][]string{sim.Names("B", 5), append(sim.Names("R", 5), sim.Names("L", 5)...)},
+			visible:  []string{"B1", "L1"},
+		},
+		{
+			name: "three monitors with duplicate names",
+			monitors: []sim.Monitor{
+				{Name: "XZ272U P (2)", Workspaces: sim.Names("R", 5)},
+				{Name: "Built-in Retina Display", IsMain: true, Workspaces: sim.Names("L", 5)},
+				{Name: "XZ272U P (1)", Workspaces: sim.Names("B", 5)},
+			},
+			expected: [][]string{sim.Names("L", 5), sim.Names("B", 5), sim.Names("R", 5)},
+			visible:  []string{"L1", "B1", "R1"},
+		},
+		{
+			name: "lid closed",
+			monitors: []sim.Monitor{
+				{Name: "DELL U2720Q", Workspaces: sim.Names("L", 5)},
+				{Name: "DELL U2720Q (2)", Workspaces: sim.Names("B", 5)},
+			},
+			expected: [][]string{sim.Names("B", 5), sim.Names("L", 5)},
+			visible:  []string{"B1", "L1"},
+		},
+		{
+			name: "four monitors",
+			monitors: []sim.Monitor{
+				{Name: "DELL U2720Q", Workspaces: []string{"X1"}},
+				{Name: "Built-in Retina Display", Workspaces: sim.Names("B", 5)},
+				{Name: "DELL U2720Q (2)", Workspaces: sim.Names("R", 5)},
+				{Name: "LG HDR 4K", Workspaces: append([]string{"S1"}, sim.Names("L", 5)...)},
+			},
+			expected: [][]string{append([]string{"X1"}, sim.Names("L", 5)...), sim.Names("B", 5), sim.Names("R", 5), {"S1"}},
+			visible:  []string{"L1", "B1", "R1", "S1"},
+		},
+		{
+			name: "sidecar doesn't count toward the layout",
+			monitors: []sim.Monitor{
+				{Name: "Built-in Retina Display", IsMain: true, Workspaces: append(sim.Names("B", 5), sim.Names("L", 5)...)},
+				{Name: "Sidecar Display (AirPlay)", Workspaces: sim.Names("R", 5)},
+				{Name: "DELL U2720Q", Workspaces: []string{"X1"}},
+			},
+			expected: [][]string{sim.Names("B", 5), {"T"}, append(append([]string{"X1"}, sim.Names("L", 5)...), sim.Names("R", 5)...)},
+			visible:  []string{"B1", "T", "L1"},
+		},
+		{
+			name: "layout rules match no monitor",
+			cfg: &config.Config{
+				Roles: []config.Role{{Name: "S", Workspaces: sim.Names("S", 5)}},
+				Layouts: []config.Layout{{Monitors: 2, Assign: []config.Assignment{
+					{Monitor: config.Match{Name: "Studio Display"}, Roles: []string{"S"}},
+				}}},
+			},
+			monitors: []sim.Monitor{
+				{Name: "Built-in Retina Display", IsMain: true, Workspaces: sim.Names("B", 5)},
+				{Name: "DELL U2720Q", Workspaces: sim.Names("S", 5)},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			if cfg == nil {
+				cfg = config.Default()
+			}
+			server := sim.New(tt.monitors...)
+			err := Execute(t.Context(), aerospace.NewClient(server), cfg, &store.Store{}, io.Discard)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Execute() error = nil, expected an error")
+				}
+				if log := server.Log(); len(log) != 0 {
+					t.Errorf("Execute() ran %v before failing, expected nothing", log)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			for i, expected := range tt.expected {
+				if got := server.WorkspacesOn(i + 1); !sameSet(got, expected) {
+					t.Errorf("Monitor %d has %v, expected %v", i+1, got, expected)
+				}
+			}
+			for i, expected := range tt.visible {
+				if got := server.VisibleOn(i + 1); got != expected {
+					t.Errorf("Monitor %d shows %q, expected %q", i+1, got, expected)
+				}
+			}
+		})
+	}
+}
+
+// sameSet reports whether a and b hold the same names in any order
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+func TestExecuteCustomConfig(t *testing.T) {
+	cfg := &config.Config{
+		Roles: []config.Role{
+			{Name: "main", Workspaces: []string{"web", "code"}},
+			{Name: "side", Workspaces: []string{"chat", "music"}},
+		},
+		Layouts: []config.Layout{{
+			Monitors: 2,
+			Assign: []config.Assignment{
+				{Monitor: config.MatchBuiltIn, Roles: []string{"side"}},
+				{Monitor: config.MatchExternal, Roles: []string{"main"}},
+			},
+			Focus: []string{"chat", "code"},
+		}},
+	}
+	server := sim.New(
+		sim.Monitor{Name: "Built-in Retina Display", IsMain: true, Workspaces: []string{"web", "chat", "scratch"}},
+		sim.Monitor{Name: "DELL U2720Q", Workspaces: []string{"music", "code"}},
+	)
+
+	if err := Execute(t.Context(), aerospace.NewClient(server), cfg, &store.Store{}, io.Discard); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// Workspaces owned by no role stay where they are
+	if got, expected := server.WorkspacesOn(1), []string{"chat", "music", "scratch"}; !sameSet(got, expected) {
+		t.Errorf("Monitor 1 has %v, expected %v", got, expected)
+	}
+	if got, expected := server.WorkspacesOn(2), []string{"web", "code"}; !sameSet(got, expected) {
+		t.Errorf("Monitor 2 has %v, expected %v", got, expected)
+	}
+	if got := server.FocusedWorkspace(); got != "code" {
+		t.Errorf("Focused %q, expected code", got)
+	}
+}
+
+func TestExecuteLabels(t *testing.T) {
+	cfg := config.Default()
+	cfg.Layouts[2].Assign = []config.Assignment{
+		{Monitor: config.MatchBuiltIn, Roles: []string{"B"}},
+		{Monitor: config.Match{Label: "left dell"}, Roles: []string{"L"}},
+		{Monitor: config.Match{Label: "right dell"}, Roles: []string{"R"}},
+	}
+
+	// Labeled while the left panel was "(1)" on screen 3
+	st := &store.Store{}
+	st.SetLabel(aerospace.Fingerprint{Name: "DELL U2720Q", AppKitID: 3, Position: 2}, "left dell")
+	st.SetLabel(aerospace.Fingerprint{Name: "DELL U2720Q", AppKitID: 2, Position: 1}, "right dell")
+
+	// After a replug macOS swapped the suffixes and Aerospace the IDs
+	server := sim.New(
+		sim.Monitor{Name: "DELL U2720Q (2)", AppKitID: 2, Workspaces: sim.Names("L", 5)},
+		sim.Monitor{Name: "Built-in Retina Display", IsMain: true, AppKitID: 1, Workspaces: sim.Names("B", 5)},
+		sim.Monitor{Name: "DELL U2720Q (1)", AppKitID: 3, Workspaces: sim.Names("R", 5)},
+	)
+
+	if err := Execute(t.Context(), aerospace.NewClient(server), cfg, st, io.Discard); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := server.WorkspacesOn(3); !sameSet(got, sim.Names("L", 5)) {
+		t.Errorf("Monitor 3 has %v, expected the L workspaces", got)
+	}
+	if got := server.WorkspacesOn(1); !sameSet(got, sim.Names("R", 5)) {
+		t.Errorf("Monitor 1 has %v, expected the R workspaces", got)
+	}
+}
@@ -0,0 +1,99 @@
+package simulate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/aerospace/sim"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/explain"
+	"github.com/Xkonti/aeromanager/internal/rearrange"
+	"github.com/Xkonti/aeromanager/internal/workspacemap"
+)
+
+// Execute plans rearranging a simulated Aerospace with the named monitors,
+// ordered left to right, and prints the topology, the plan rearrange
+// --dry-run would print and the key to workspace grid of every monitor.
+// All role workspaces start out on the main monitor, as after a replug.
+// mouse and main are 1-based monitor IDs, 0 meaning the first monitor.
+func Execute(ctx context.Context, cfg *config.Config, w io.Writer, monitorNames []string, mouse int, main int) error {
+	if len(monitorNames) == 0 {
+		return fmt.Errorf("simulate requires at least one monitor")
+	}
+	if mouse < 0 || mouse > len(monitorNames) {
+		return fmt.Errorf("invalid mouse monitor: %d (must be 1-%d)", mouse, len(monitorNames))
+	}
+	if main < 0 || main > len(monitorNames) {
+		return fmt.Errorf("invalid main monitor: %d (must be 1-%d)", main, len(monitorNames))
+	}
+	if main == 0 {
+		main = 1
+	}
+	if mouse == 0 {
+		mouse = main
+	}
+
+	monitors := make([]sim.Monitor, len(monitorNames))
+	for i, name := range monitorNames {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return fmt.Errorf("invalid monitor name at position %d: names can't be empty (usage: --monitors \"name,name,...\")", i+1)
+		}
+		monitors[i] = sim.Monitor{Name: name, IsMain: i+1 == main}
+	}
+	for _, role := range cfg.Roles {
+		monitors[main-1].Workspaces = append(monitors[main-1].Workspaces, role.Workspaces...)
+	}
+
+	state, err := aerospace.NewClient(sim.New(monitors...)).Snapshot(ctx, aerospace.SnapshotOptions{})
+	if err != nil {
+		return err
+	}
+	plan, err := rearrange.NewPlan(cfg, state, nil)
+	if err != nil {
+		return err
+	}
+	topo := plan.Topology
+
+	explain.PrintTopology(w, cfg, topo)
+	fmt.Fprintln(w)
+	plan.Print(w)
+	fmt.Fprintln(w)
+
+	strategy := workspacemap.Strategy(cfg, topo)
+	fmt.Fprintf(w, "Keys (%s mapping):\n", cfg.MappingFor(topo.Layout))
+	// Transient monitors last, every key reaches their workspace
+	grid := slices.Clone(topo.Monitors)
+	for _, tr := range topo.Transient {
+		grid = append(grid, tr.Monitor)
+	}
+	for _, mon := range grid {
+		marker := ""
+		if mon.ID == mouse {
+			marker = ", mouse"
+		}
+		pages := strategy.Pages(mon.ID, topo)
+		if pages == 0 {
+			fmt.Fprintf(w, "  Monitor %d %q%s: no roles\n", mon.ID, mon.Name, marker)
+			continue
+		}
+		for page := 1; page <= pages; page++ {
+			fmt.Fprintf(w, "  Monitor %d %q%s, page %d:", mon.ID, mon.Name, marker, page)
+			// Keys in keyboard order, 0 last
+			for _, num := range []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 0} {
+				name, err := strategy.Map(num, page, mon.ID, topo)
+				if err != nil {
+					name = "-"
+				}
+				fmt.Fprintf(w, " %d:%s", num, name)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	return nil
+}
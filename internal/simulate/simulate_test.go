@@ -0,0 +1,65 @@
+package simulate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/config"
+)
+
+func TestExecute(t *testing.T) {
+	var out strings.Builder
+	monitors := []string{"DELL U2720Q", "Built-in Retina Display", "LG HDR 4K"}
+	if err := Execute(t.Context(), config.Default(), &out, monitors, 3, 2); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	for _, expected := range []string{
+		`Monitor 2 "Built-in Retina Display" (main): B (matched builtin)`,
+		"  Move workspace L1 from monitor 2 to monitor 1\n",
+		"  Move workspace R5 from monitor 2 to monitor 3\n",
+		`Monitor 3 "LG HDR 4K", mouse, page 1: 1:R1 2:R2 3:R3 4:R4 5:R5 6:R1 7:R2 8:R3 9:R4 0:R5`,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Execute() printed:\n%s\nexpected it to contain %q", out.String(), expected)
+		}
+	}
+	if strings.Contains(out.String(), "Move workspace B1") {
+		t.Errorf("Execute() moved B1 off the main monitor it already lives on:\n%s", out.String())
+	}
+}
+
+func TestExecuteInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		monitors []string
+		mouse    int
+	}{
+		{name: "no monitors"},
+		{name: "mouse out of range", monitors: []string{"DELL U2720Q"}, mouse: 2},
+		{name: "unsupported layout", monitors: []string{"DELL U2720Q", "DELL U2720Q (2)"}, mouse: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Docked.Primary = &config.Match{Name: "Studio"}
+			if err := Execute(t.Context(), cfg, &strings.Builder{}, tt.monitors, tt.mouse, 0); err == nil {
+				t.Errorf("Execute() error = nil, expected an error")
+			}
+		})
+	}
+}
+
+func TestExecuteEmptyMonitorName(t *testing.T) {
+	for _, monitors := range []string{",,", "DELL U2720Q, ,LG HDR 4K"} {
+		var out strings.Builder
+		err := Execute(t.Context(), config.Default(), &out, strings.Split(monitors, ","), 0, 0)
+		if err == nil || !strings.Contains(err.Error(), "names can't be empty") {
+			t.Errorf("Execute(%q) error = %v, expected an empty name error", monitors, err)
+		}
+		if out.Len() != 0 {
+			t.Errorf("Execute(%q) printed:\n%s\nexpected nothing", monitors, out.String())
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+)
+
+// Store is what aeromanager remembers between invocations, saved as JSON
+type Store struct {
+	Pages  map[string]int `json:"pages,omitempty"`  // Current page per monitor, see PageKey
+	Labels []Label        `json:"labels,omitempty"` // Names given to physical monitors
+	// Profile forced with aeromanager profile use, empty to pick profiles by monitor set
+	Profile string `json:"profile,omitempty"`
+
+	path string
+}
+
+// Label names the physical monitor with the fingerprint, such as "left dell"
+type Label struct {
+	aerospace.Fingerprint
+	Label string `json:"label"`
+}
+
+// DefaultPath returns where the state file lives: $XDG_STATE_HOME/aeromanager/state.json,
+// falling back to ~/.local/state/aeromanager/state.json
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "aeromanager", "state.json"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "aeromanager", "state.json"), nil
+}
+
+// New returns an empty store saved to path, whatever the file holds
+func New(path string) *Store {
+	return &Store{path: path}
+}
+
+// Open reads the state file at path, starting empty when it doesn't exist
+func Open(path string) (*Store, error) {
+	s := New(path)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid state %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes the state file, replacing it atomically
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// PageKey returns the key the monitor's page is remembered by: its label when
+// it has one, otherwise its fingerprint. Unlike the monitor name, it stays
+// with the monitor when macOS swaps the " (n)" suffixes of identical monitors.
+func (s *Store) PageKey(monitors []aerospace.Monitor, monitorID int) string {
+	if label, ok := s.Identify(monitors)[monitorID]; ok {
+		return "label:" + label
+	}
+	return aerospace.Fingerprints(monitors)[monitorID].String()
+}
+
+// Page returns the current page of a monitor by its PageKey, 1 unless set
+// otherwise. A remembered page beyond the monitor's pages, left over from a
+// config with more workspaces or a monitor that hosted a bigger role, is 1 too.
+func (s *Store) Page(monitor string, pages int) int {
+	if page, ok := s.Pages[monitor]; ok && page >= 1 && page <= pages {
+		return page
+	}
+	return 1
+}
+
+// SetPage remembers the current page of a monitor by its PageKey
+func (s *Store) SetPage(monitor string, page int) {
+	if s.Pages == nil {
+		s.Pages = make(map[string]int)
+	}
+	if page <= 1 {
+		delete(s.Pages, monitor)
+		return
+	}
+	s.Pages[monitor] = page
+}
+
+// SetLabel names the monitor with the fingerprint, taking the label away from
+// any other monitor. An empty label forgets the monitor.
+func (s *Store) SetLabel(fp aerospace.Fingerprint, label string) {
+	kept := s.Labels[:0]
+	for _, l := range s.Labels {
+		if l.Fingerprint != fp && l.Label != label {
+			kept = append(kept, l)
+		}
+	}
+	s.Labels = kept
+	if label != "" {
+		s.Labels = append(s.Labels, Label{Fingerprint: fp, Label: label})
+	}
+}
+
+// Identify returns the label of every connected monitor that has one, by
+// monitor ID. Known fingerprints are matched to monitors best match first, so
+// a monitor still gets its label after macOS renumbered its screen or
+// swapped the suffixes of identically named monitors.
+func (s *Store) Identify(monitors []aerospace.Monitor) map[int]string {
+	type candidate struct {
+		id, label, score int
+	}
+	var candidates []candidate
+	for id, fp := range aerospace.Fingerprints(monitors) {
+		for i, l := range s.Labels {
+			if score := fp.Similarity(l.Fingerprint); score > 0 {
+				candidates = append(candidates, candidate{id: id, label: i, score: score})
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.id != b.id {
+			return a.id < b.id
+		}
+		return a.label < b.label
+	})
+
+	labels := make(map[int]string)
+	taken := make(map[int]bool)
+	for _, c := range candidates {
+		if _, ok := labels[c.id]; ok || taken[c.label] {
+			continue
+		}
+		labels[c.id] = s.Labels[c.label].Label
+		taken[c.label] = true
+	}
+	return labels
+}
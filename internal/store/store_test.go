@@ -0,0 +1,155 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+)
+
+func TestPagesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+
+	st, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() of a missing file error = %v", err)
+	}
+	if page := st.Page("DELL U2720Q", 3); page != 1 {
+		t.Errorf("Page() = %d, expected 1 by default", page)
+	}
+
+	st.SetPage("DELL U2720Q", 3)
+	st.SetPage("Built-in Retina Display", 2)
+	st.SetPage("Built-in Retina Display", 1)
+	if err := st.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	st, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if page := st.Page("DELL U2720Q", 3); page != 3 {
+		t.Errorf("Page(DELL U2720Q) = %d, expected 3", page)
+	}
+	if page := st.Page("DELL U2720Q", 2); page != 1 {
+		t.Errorf("Page(DELL U2720Q) with 2 pages = %d, expected 1", page)
+	}
+	if _, ok := st.Pages["Built-in Retina Display"]; ok {
+		t.Errorf("Page 1 was stored, expected it to be the implicit default")
+	}
+}
+
+func TestPageKey(t *testing.T) {
+	st := &Store{}
+	st.SetLabel(aerospace.Fingerprint{Name: "LG HDR 4K", AppKitID: 3, Position: 1}, "side")
+	before := []aerospace.Monitor{
+		{ID: 1, Name: "DELL U2720Q (1)", AppKitID: 1},
+		{ID: 2, Name: "DELL U2720Q (2)", AppKitID: 2},
+		{ID: 3, Name: "LG HDR 4K", AppKitID: 3},
+	}
+	st.SetPage(st.PageKey(before, 1), 2)
+	st.SetPage(st.PageKey(before, 3), 3)
+
+	// macOS swapped the suffixes and renumbered the LG's screen
+	after := []aerospace.Monitor{
+		{ID: 1, Name: "DELL U2720Q (2)", AppKitID: 1},
+		{ID: 2, Name: "DELL U2720Q (1)", AppKitID: 2},
+		{ID: 3, Name: "LG HDR 4K", AppKitID: 4},
+	}
+	for id, expected := range map[int]int{1: 2, 2: 1, 3: 3} {
+		if page := st.Page(st.PageKey(after, id), 3); page != expected {
+			t.Errorf("Page(PageKey(%d)) = %d, expected %d", id, page, expected)
+		}
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Errorf("Open() of invalid JSON error = nil, expected an error")
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	st, err := Open(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Labeled while the left panel was reported as "(2)" on screen 2
+	labeled := []aerospace.Monitor{
+		{ID: 1, Name: "XZ272U P (2)", AppKitID: 2},
+		{ID: 2, Name: "Built-in Retina Display", AppKitID: 1},
+		{ID: 3, Name: "XZ272U P (1)", AppKitID: 3},
+	}
+	fps := aerospace.Fingerprints(labeled)
+	st.SetLabel(fps[1], "left")
+	st.SetLabel(fps[3], "right")
+	st.SetLabel(fps[2], "laptop")
+	st.SetLabel(fps[2], "")
+
+	tests := []struct {
+		name     string
+		monitors []aerospace.Monitor
+		expected map[int]string
+	}{
+		{
+			name:     "same setup",
+			monitors: labeled,
+			expected: map[int]string{1: "left", 3: "right"},
+		},
+		{
+			name: "suffixes swapped after a replug",
+			monitors: []aerospace.Monitor{
+				{ID: 1, Name: "XZ272U P (1)", AppKitID: 2},
+				{ID: 2, Name: "Built-in Retina Display", AppKitID: 1},
+				{ID: 3, Name: "XZ272U P (2)", AppKitID: 3},
+			},
+			expected: map[int]string{1: "left", 3: "right"},
+		},
+		{
+			name: "screens renumbered",
+			monitors: []aerospace.Monitor{
+				{ID: 1, Name: "XZ272U P (2)", AppKitID: 3},
+				{ID: 2, Name: "XZ272U P (1)", AppKitID: 2},
+			},
+			expected: map[int]string{1: "right", 2: "left"},
+		},
+		{
+			name:     "only one panel connected",
+			monitors: []aerospace.Monitor{{ID: 1, Name: "XZ272U P", AppKitID: 3}},
+			expected: map[int]string{1: "right"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := st.Identify(tt.monitors)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Identify() = %v, expected %v", got, tt.expected)
+			}
+			for id, label := range tt.expected {
+				if got[id] != label {
+					t.Errorf("Identify() = %v, expected %v", got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestSetLabelMovesLabel(t *testing.T) {
+	st := &Store{}
+	left := aerospace.Fingerprint{Name: "DELL U2720Q", AppKitID: 2, Position: 1}
+	right := aerospace.Fingerprint{Name: "DELL U2720Q", AppKitID: 3, Position: 2}
+
+	st.SetLabel(left, "main")
+	st.SetLabel(right, "main")
+	if len(st.Labels) != 1 || st.Labels[0].Fingerprint != right {
+		t.Errorf("Labels = %+v, expected only the right monitor labeled", st.Labels)
+	}
+}
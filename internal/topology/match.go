@@ -0,0 +1,140 @@
+package topology
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+)
+
+// matcher evaluates monitor rules against a concrete set of monitors
+type matcher struct {
+	monitors []aerospace.Monitor // Left to right
+	labels   map[int]string      // Labels of the physical monitors by ID
+	builtIn  map[int]bool        // IDs of the monitors identified as built-in
+	docked   bool                // No built-in display, the docked primary stands in for it
+	patterns map[string]*regexp.Regexp
+}
+
+// newMatcher identifies the built-in displays using the first built-in rule
+// that matches any monitor. Without one, the docked primary display is
+// treated as built-in instead.
+func newMatcher(cfg *config.Config, monitors []aerospace.Monitor, labels map[int]string) (*matcher, error) {
+	m := &matcher{
+		monitors: monitors,
+		labels:   labels,
+		builtIn:  make(map[int]bool),
+		patterns: make(map[string]*regexp.Regexp),
+	}
+
+	for _, rule := range cfg.BuiltInRules() {
+		for _, mon := range monitors {
+			ok, err := m.matches(rule, mon)
+			if err != nil {
+				return nil, fmt.Errorf("builtin: %w", err)
+			}
+			if ok {
+				m.builtIn[mon.ID] = true
+			}
+		}
+		if len(m.builtIn) > 0 {
+			return m, nil
+		}
+	}
+
+	// Docked: the primary external display takes over the built-in display's roles
+	primary, _, ok, err := m.pick(cfg.DockedPrimary(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("docked primary: %w", err)
+	}
+	if ok {
+		m.builtIn[primary.ID] = true
+		m.docked = true
+	}
+
+	return m, nil
+}
+
+// pick returns the leftmost unused monitor satisfying the rule or, failing
+// that, one of its fallbacks. The rule that made the pick is returned too.
+func (m *matcher) pick(rule config.Match, used map[int]bool) (aerospace.Monitor, config.Match, bool, error) {
+	for _, mon := range m.monitors {
+		if used[mon.ID] {
+			continue
+		}
+		ok, err := m.matches(rule, mon)
+		if err != nil {
+			return aerospace.Monitor{}, rule, false, err
+		}
+		if ok {
+			rule.Fallback = nil
+			return mon, rule, true, nil
+		}
+	}
+
+	for _, fallback := range rule.Fallback {
+		mon, matched, ok, err := m.pick(fallback, used)
+		if err != nil || ok {
+			return mon, matched, ok, err
+		}
+	}
+	return aerospace.Monitor{}, rule, false, nil
+}
+
+// matches reports whether the monitor satisfies every condition of the rule,
+// ignoring its fallbacks
+func (m *matcher) matches(rule config.Match, mon aerospace.Monitor) (bool, error) {
+	if rule.Name != "" {
+		pattern, err := m.pattern(rule.Name)
+		if err != nil {
+			return false, err
+		}
+		if !pattern.MatchString(mon.Name) {
+			return false, nil
+		}
+	}
+	if rule.Label != "" && m.labels[mon.ID] != rule.Label {
+		return false, nil
+	}
+	if rule.Position != "" && !m.at(rule.Position, mon) {
+		return false, nil
+	}
+	if rule.Main != nil && mon.IsMain != *rule.Main {
+		return false, nil
+	}
+	if rule.BuiltIn != nil && m.builtIn[mon.ID] != *rule.BuiltIn {
+		return false, nil
+	}
+	return true, nil
+}
+
+// at reports whether the monitor is at the given position among all monitors
+func (m *matcher) at(position string, mon aerospace.Monitor) bool {
+	index := -1
+	switch position {
+	case config.PositionLeftmost:
+		index = 0
+	case config.PositionRightmost:
+		index = len(m.monitors) - 1
+	default:
+		if n, err := strconv.Atoi(position); err == nil {
+			index = n - 1
+		}
+	}
+	return index >= 0 && index < len(m.monitors) && m.monitors[index].ID == mon.ID
+}
+
+// pattern compiles a name pattern once per resolution
+func (m *matcher) pattern(expr string) (*regexp.Regexp, error) {
+	if pattern, ok := m.patterns[expr]; ok {
+		return pattern, nil
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name pattern %q: %w", expr, err)
+	}
+	m.patterns[expr] = pattern
+	return pattern, nil
+}
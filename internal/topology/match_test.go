@@ -0,0 +1,121 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+)
+
+func TestDefaultBuiltInNames(t *testing.T) {
+	for _, name := range []string{
+		"Built-in Retina Display",
+		"Built-in Liquid Retina XDR Display",
+		"Color LCD",
+		"Écran intégré",
+		"Integriertes Retina-Display",
+		"Pantalla integrada",
+		"内蔵Retinaディスプレイ",
+		"内建视网膜显示器",
+	} {
+		m, err := newMatcher(config.Default(), []aerospace.Monitor{{ID: 1, Name: name}, {ID: 2, Name: "DELL U2720Q"}}, nil)
+		if err != nil {
+			t.Fatalf("newMatcher() error = %v", err)
+		}
+		if !m.builtIn[1] || m.builtIn[2] {
+			t.Errorf("Built-in displays for %q = %v, expected only monitor 1", name, m.builtIn)
+		}
+	}
+
+	m, err := newMatcher(config.Default(), []aerospace.Monitor{{ID: 1, Name: "Sidecar Display (AirPlay)"}}, nil)
+	if err != nil {
+		t.Fatalf("newMatcher() error = %v", err)
+	}
+	if !m.docked {
+		t.Errorf("Sidecar display identified as built-in")
+	}
+}
+
+func TestPick(t *testing.T) {
+	yes, no := true, false
+	monitors := []aerospace.Monitor{
+		{ID: 1, Name: "DELL U2720Q"},
+		{ID: 2, Name: "Built-in Retina Display", IsMain: true},
+		{ID: 3, Name: "LG HDR 4K"},
+		{ID: 4, Name: "DELL U2720Q (2)"},
+	}
+
+	tests := []struct {
+		name     string
+		rule     config.Match
+		used     []int
+		expected int // Picked monitor ID, 0 for none
+	}{
+		{name: "any", rule: config.MatchAny, expected: 1},
+		{name: "any skips used", rule: config.MatchAny, used: []int{1, 2}, expected: 3},
+		{name: "built-in", rule: config.MatchBuiltIn, expected: 2},
+		{name: "external", rule: config.MatchExternal, used: []int{1}, expected: 3},
+		{name: "name pattern", rule: config.Match{Name: `^DELL`}, used: []int{1}, expected: 4},
+		{name: "leftmost", rule: config.Match{Position: config.PositionLeftmost}, expected: 1},
+		{name: "rightmost", rule: config.Match{Position: config.PositionRightmost}, expected: 4},
+		{name: "nth", rule: config.Match{Position: "3"}, expected: 3},
+		{name: "position beyond monitors", rule: config.Match{Position: "7"}},
+		{name: "main", rule: config.Match{Main: &yes}, expected: 2},
+		{name: "combined conditions", rule: config.Match{Name: "DELL", Main: &no, Position: config.PositionRightmost}, expected: 4},
+		{name: "used position", rule: config.Match{Position: config.PositionLeftmost}, used: []int{1}},
+		{
+			name: "fallbacks in order",
+			rule: config.Match{Name: "Studio Display", Fallback: []config.Match{
+				{Name: "Pro Display XDR"},
+				{Name: "LG"},
+				config.MatchAny,
+			}},
+			expected: 3,
+		},
+	}
+
+	m, err := newMatcher(config.Default(), monitors, nil)
+	if err != nil {
+		t.Fatalf("newMatcher() error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used := make(map[int]bool)
+			for _, id := range tt.used {
+				used[id] = true
+			}
+			mon, _, ok, err := m.pick(tt.rule, used)
+			if err != nil {
+				t.Fatalf("pick() error = %v", err)
+			}
+			got := 0
+			if ok {
+				got = mon.ID
+			}
+			if got != tt.expected {
+				t.Errorf("pick(%s) = %d, expected %d", tt.rule, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveCustomBuiltInRules(t *testing.T) {
+	yes := true
+	cfg := config.Default()
+	// Treat the main display as built-in when no display carries a built-in name
+	cfg.BuiltIn = append(append([]config.Match{}, config.DefaultBuiltIn...), config.Match{Main: &yes})
+
+	topo, err := Resolve(cfg, []aerospace.Monitor{
+		{ID: 1, Name: "Studio Display", IsMain: true},
+		{ID: 2, Name: "DELL U2720Q"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if roles := topo.RolesOn(1); len(roles) != 1 || roles[0].Name != "B" {
+		t.Errorf("RolesOn(1) = %v, expected B", roles)
+	}
+	if got := topo.Assignments[0].Rule.String(); got != config.SelectBuiltIn {
+		t.Errorf("Assignments[0].Rule = %s, expected %s", got, config.SelectBuiltIn)
+	}
+}
@@ -0,0 +1,212 @@
+package topology
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+)
+
+// Point is a monitor's cell in the physical arrangement, X grows to the
+// right and Y downwards
+type Point struct {
+	X, Y int
+}
+
+// Arrange returns the permanent monitors in the left to right order Resolve
+// uses, which is what position rules count in
+func Arrange(cfg *config.Config, monitors []aerospace.Monitor, labels map[int]string) ([]aerospace.Monitor, error) {
+	permanent, _, err := splitTransient(cfg, monitors, labels)
+	if err != nil {
+		return nil, err
+	}
+	ordered, _, err := arrange(cfg, permanent, labels)
+	return ordered, err
+}
+
+// arrange places monitors on a grid using the configured placements and
+// returns them ordered left to right, top to bottom within a column.
+// Placements are constraints between two monitors and may come in any order,
+// monitors linked by them form a group laid out as a whole, and groups are
+// lined up left to right in the order the placements mention them. Monitors
+// without a placement are lined up to the right in ID order, so without any
+// placement the order is Aerospace's ID order. Placements that contradict
+// each other or put two monitors in the same spot are an error.
+func arrange(cfg *config.Config, monitors []aerospace.Monitor, labels map[int]string) ([]aerospace.Monitor, map[int]Point, error) {
+	points := make(map[int]Point, len(monitors))
+	nextX := 0
+	place := func(id int, p Point) {
+		points[id] = p
+		nextX = max(nextX, p.X+1)
+	}
+
+	if len(cfg.Placement) > 0 {
+		m, err := newMatcher(cfg, monitors, labels)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var links []link
+		placed := make(map[int]bool)
+		for i, p := range cfg.Placement {
+			subject, _, ok, err := m.pick(p.Monitor, nil)
+			if err != nil {
+				return nil, nil, fmt.Errorf("placement %d: %w", i+1, err)
+			}
+			reference, _, refOK, err := m.pick(p.Of, nil)
+			if err != nil {
+				return nil, nil, fmt.Errorf("placement %d: %w", i+1, err)
+			}
+			// Placements of disconnected monitors don't apply, and the first placement of a monitor wins
+			if !ok || !refOK || subject.ID == reference.ID || placed[subject.ID] {
+				continue
+			}
+			placed[subject.ID] = true
+			links = append(links, link{placement: i + 1, subject: subject, reference: reference, offset: sideOffset(p.Side)})
+		}
+
+		for _, l := range links {
+			if _, done := points[l.reference.ID]; done {
+				continue
+			}
+			group, err := layoutGroup(l.reference, links)
+			if err != nil {
+				return nil, nil, err
+			}
+			left := 0
+			for _, p := range group {
+				left = min(left, p.X)
+			}
+			base := nextX - left
+			for id, p := range group {
+				place(id, Point{X: base + p.X, Y: p.Y})
+			}
+		}
+	}
+
+	for _, mon := range monitors {
+		if _, placed := points[mon.ID]; !placed {
+			place(mon.ID, Point{X: nextX})
+		}
+	}
+
+	ordered := slices.Clone(monitors)
+	slices.SortStableFunc(ordered, func(a, b aerospace.Monitor) int {
+		pa, pb := points[a.ID], points[b.ID]
+		if pa.X != pb.X {
+			return pa.X - pb.X
+		}
+		if pa.Y != pb.Y {
+			return pa.Y - pb.Y
+		}
+		return a.ID - b.ID
+	})
+	return ordered, points, nil
+}
+
+// link is a placement between two connected monitors: the subject sits at
+// the reference's point moved by offset
+type link struct {
+	placement int // 1-based index in the config, for errors
+	subject   aerospace.Monitor
+	reference aerospace.Monitor
+	offset    Point
+}
+
+// sideOffset returns the move from a reference to the monitor on the given side of it
+func sideOffset(side string) Point {
+	switch side {
+	case config.SideLeft:
+		return Point{X: -1}
+	case config.SideRight:
+		return Point{X: 1}
+	case config.SideAbove:
+		return Point{Y: -1}
+	case config.SideBelow:
+		return Point{Y: 1}
+	}
+	return Point{}
+}
+
+// layoutGroup places every monitor linked to root, directly or through other
+// monitors, relative to root at the origin
+func layoutGroup(root aerospace.Monitor, links []link) (map[int]Point, error) {
+	group := map[int]Point{root.ID: {}}
+	owner := map[Point]aerospace.Monitor{{}: root}
+	queue := []int{root.ID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		from := group[id]
+
+		for _, l := range links {
+			var other aerospace.Monitor
+			var at Point
+			switch id {
+			case l.reference.ID:
+				other, at = l.subject, Point{X: from.X + l.offset.X, Y: from.Y + l.offset.Y}
+			case l.subject.ID:
+				other, at = l.reference, Point{X: from.X - l.offset.X, Y: from.Y - l.offset.Y}
+			default:
+				continue
+			}
+
+			if p, ok := group[other.ID]; ok {
+				if p != at {
+					return nil, fmt.Errorf("placement %d contradicts the other placements of %s and %s", l.placement, l.subject.Name, l.reference.Name)
+				}
+				continue
+			}
+			if taken, ok := owner[at]; ok {
+				return nil, fmt.Errorf("placement %d puts %s in the same spot as %s", l.placement, other.Name, taken.Name)
+			}
+			group[other.ID], owner[at] = at, other
+			queue = append(queue, other.ID)
+		}
+	}
+	return group, nil
+}
+
+// Neighbor returns the closest monitor on the given side of a monitor,
+// preferring monitors in the same row or column
+func (t *Topology) Neighbor(monitorID int, side string) (aerospace.Monitor, bool) {
+	from, ok := t.Points[monitorID]
+	if !ok {
+		return aerospace.Monitor{}, false
+	}
+
+	var best aerospace.Monitor
+	bestAlong, bestAcross := 0, 0
+	found := false
+	for _, mon := range t.Monitors {
+		p := t.Points[mon.ID]
+		var along, across int
+		switch side {
+		case config.SideLeft:
+			along, across = from.X-p.X, abs(p.Y-from.Y)
+		case config.SideRight:
+			along, across = p.X-from.X, abs(p.Y-from.Y)
+		case config.SideAbove:
+			along, across = from.Y-p.Y, abs(p.X-from.X)
+		case config.SideBelow:
+			along, across = p.Y-from.Y, abs(p.X-from.X)
+		default:
+			return aerospace.Monitor{}, false
+		}
+		if along <= 0 {
+			continue
+		}
+		if !found || across < bestAcross || (across == bestAcross && along < bestAlong) {
+			best, bestAlong, bestAcross, found = mon, along, across, true
+		}
+	}
+	return best, found
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,203 @@
+package topology
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+)
+
+func TestArrange(t *testing.T) {
+	monitors := []aerospace.Monitor{
+		{ID: 1, Name: "DELL U2720Q"},
+		{ID: 2, Name: "Built-in Retina Display"},
+		{ID: 3, Name: "LG HDR 4K"},
+	}
+
+	tests := []struct {
+		name      string
+		placement []config.Placement
+		expected  []int // Monitor IDs left to right
+	}{
+		{name: "ID order without placement", expected: []int{1, 2, 3}},
+		{
+			name:      "placed left of the built-in display",
+			placement: []config.Placement{{Monitor: config.Match{Name: "LG"}, Side: config.SideLeft, Of: config.MatchBuiltIn}},
+			expected:  []int{3, 2, 1},
+		},
+		{
+			name: "stacked above",
+			placement: []config.Placement{
+				{Monitor: config.Match{Name: "DELL"}, Side: config.SideAbove, Of: config.MatchBuiltIn},
+				{Monitor: config.Match{Name: "LG"}, Side: config.SideRight, Of: config.MatchBuiltIn},
+			},
+			expected: []int{1, 2, 3},
+		},
+		{
+			name: "first placement wins",
+			placement: []config.Placement{
+				{Monitor: config.Match{Name: "DELL"}, Side: config.SideRight, Of: config.MatchBuiltIn},
+				{Monitor: config.Match{Name: "DELL"}, Side: config.SideLeft, Of: config.MatchBuiltIn},
+			},
+			expected: []int{2, 1, 3},
+		},
+		{
+			name: "chain in reverse order",
+			placement: []config.Placement{
+				{Monitor: config.Match{Name: "LG"}, Side: config.SideRight, Of: config.MatchBuiltIn},
+				{Monitor: config.MatchBuiltIn, Side: config.SideRight, Of: config.Match{Name: "DELL"}},
+			},
+			expected: []int{1, 2, 3},
+		},
+		{
+			name: "chain placed leftwards",
+			placement: []config.Placement{
+				{Monitor: config.Match{Name: "DELL"}, Side: config.SideRight, Of: config.MatchBuiltIn},
+				{Monitor: config.MatchBuiltIn, Side: config.SideRight, Of: config.Match{Name: "LG"}},
+			},
+			expected: []int{3, 2, 1},
+		},
+		{
+			name:      "disconnected monitor is ignored",
+			placement: []config.Placement{{Monitor: config.Match{Name: "Studio"}, Side: config.SideLeft, Of: config.MatchBuiltIn}},
+			expected:  []int{1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Placement = tt.placement
+
+			ordered, _, err := arrange(cfg, monitors, nil)
+			if err != nil {
+				t.Fatalf("arrange() error = %v", err)
+			}
+			var got []int
+			for _, mon := range ordered {
+				got = append(got, mon.ID)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("arrange() order = %v, expected %v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("arrange() order = %v, expected %v", got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestArrangeInvalid(t *testing.T) {
+	monitors := []aerospace.Monitor{
+		{ID: 1, Name: "DELL U2720Q"},
+		{ID: 2, Name: "Built-in Retina Display"},
+		{ID: 3, Name: "LG HDR 4K"},
+	}
+
+	tests := []struct {
+		name      string
+		placement []config.Placement
+		expected  string // Substring of the error
+	}{
+		{
+			name: "two monitors in the same spot",
+			placement: []config.Placement{
+				{Monitor: config.Match{Name: "DELL"}, Side: config.SideRight, Of: config.MatchBuiltIn},
+				{Monitor: config.Match{Name: "LG"}, Side: config.SideRight, Of: config.MatchBuiltIn},
+			},
+			expected: "placement 2 puts LG HDR 4K in the same spot as DELL U2720Q",
+		},
+		{
+			name: "contradicting placements",
+			placement: []config.Placement{
+				{Monitor: config.Match{Name: "DELL"}, Side: config.SideRight, Of: config.MatchBuiltIn},
+				{Monitor: config.Match{Name: "LG"}, Side: config.SideRight, Of: config.Match{Name: "DELL"}},
+				{Monitor: config.MatchBuiltIn, Side: config.SideRight, Of: config.Match{Name: "LG"}},
+			},
+			expected: "contradicts the other placements",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Placement = tt.placement
+
+			_, _, err := arrange(cfg, monitors, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("arrange() error = %v, expected it to contain %q", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolvePlacement(t *testing.T) {
+	monitors := []aerospace.Monitor{
+		{ID: 1, Name: "DELL U2720Q"},
+		{ID: 2, Name: "Built-in Retina Display"},
+		{ID: 3, Name: "DELL U2720Q (2)"},
+	}
+	cfg := config.Default()
+	cfg.Placement = []config.Placement{
+		{Monitor: config.Match{Name: `\(2\)`}, Side: config.SideLeft, Of: config.MatchBuiltIn},
+	}
+
+	topo, err := Resolve(cfg, monitors, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	for id, role := range map[int]string{3: "L", 2: "B", 1: "R"} {
+		if roles := topo.RolesOn(id); len(roles) != 1 || roles[0].Name != role {
+			t.Errorf("RolesOn(%d) = %v, expected %s", id, roles, role)
+		}
+	}
+}
+
+func TestNeighbor(t *testing.T) {
+	// DELL sits above the built-in display, LG to its right
+	monitors := []aerospace.Monitor{
+		{ID: 1, Name: "Built-in Retina Display"},
+		{ID: 2, Name: "DELL U2720Q"},
+		{ID: 3, Name: "LG HDR 4K"},
+	}
+	cfg := config.Default()
+	cfg.Placement = []config.Placement{
+		{Monitor: config.Match{Name: "DELL"}, Side: config.SideAbove, Of: config.MatchBuiltIn},
+		{Monitor: config.Match{Name: "LG"}, Side: config.SideRight, Of: config.MatchBuiltIn},
+	}
+
+	topo, err := Resolve(cfg, monitors, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	tests := []struct {
+		from     int
+		side     string
+		expected int // 0 for no neighbor
+	}{
+		{from: 1, side: config.SideAbove, expected: 2},
+		{from: 2, side: config.SideBelow, expected: 1},
+		{from: 1, side: config.SideRight, expected: 3},
+		{from: 3, side: config.SideLeft, expected: 1},
+		{from: 2, side: config.SideRight, expected: 3},
+		{from: 1, side: config.SideLeft},
+		{from: 3, side: config.SideBelow},
+	}
+
+	for _, tt := range tests {
+		got, ok := topo.Neighbor(tt.from, tt.side)
+		if tt.expected == 0 {
+			if ok {
+				t.Errorf("Neighbor(%d, %s) = %d, expected none", tt.from, tt.side, got.ID)
+			}
+			continue
+		}
+		if !ok || got.ID != tt.expected {
+			t.Errorf("Neighbor(%d, %s) = %d, %v, expected %d", tt.from, tt.side, got.ID, ok, tt.expected)
+		}
+	}
+}
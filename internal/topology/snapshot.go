@@ -0,0 +1,30 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/store"
+)
+
+// Snapshot takes a consistent snapshot of workspaces, monitors and the mouse
+// monitor, and resolves the topology of the monitors with the labels from st.
+// Why a forced profile was set aside, if it was, is printed to w.
+func Snapshot(ctx context.Context, client *aerospace.Client, cfg *config.Config, st *store.Store, w io.Writer) (*aerospace.State, *Topology, error) {
+	state, err := client.Snapshot(ctx, aerospace.SnapshotOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	topo, err := Resolve(cfg, state.Monitors, st.Identify(state.Monitors))
+	if err != nil {
+		return nil, nil, err
+	}
+	if topo.Warning != "" {
+		fmt.Fprintf(w, "Warning: %s\n", topo.Warning)
+	}
+	return state, topo, nil
+}
@@ -0,0 +1,196 @@
+package topology
+
+import (
+	"fmt"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+)
+
+// Assignment is a monitor together with the roles it hosts
+type Assignment struct {
+	Monitor aerospace.Monitor
+	Rule    config.Match   // The rule, or fallback, that picked the monitor
+	Roles   []*config.Role // Keys 1-5 reach the first role, 6-0 the second (or the first again)
+	Reason  string         // Why the monitor got its roles, for explaining the assignment
+}
+
+// Topology is the role assignment for a concrete set of monitors
+type Topology struct {
+	Monitors    []aerospace.Monitor // Permanent monitors, left to right as placed
+	Points      map[int]Point       // Physical position of each monitor by ID
+	Labels      map[int]string      // Labels of the physical monitors by ID, see store.Identify
+	Layout      *config.Layout
+	Profile     *config.Profile     // Profile the layout comes from, nil when picked by monitor count
+	Generated   bool                // No layout is declared for the monitor count, roles were handed out left to right
+	Assignments []Assignment        // In the order of the layout's assignments
+	Spare       []aerospace.Monitor // Monitors left without roles, left to right
+	Transient   []Transient         // Monitors that come and go, in ID order
+	Docked      bool                // No built-in display is connected
+	Primary     int                 // ID of the display standing in for the built-in one while docked
+	Warning     string              // Why the forced profile was set aside, empty when it wasn't
+}
+
+// Resolve assigns the roles of the profile matching the set of monitors, or
+// else of the layout matching their number, falling back to handing roles out
+// left to right when none is declared.
+// Monitors are ordered by the configured placement, or kept in the ID order
+// returned by ListMonitors when there is none. Transient monitors are set
+// aside first and don't count toward the layout. labels names the physical
+// monitors for rules with a label, it may be nil.
+// A forced profile the connected monitors can't satisfy is set aside with a
+// warning, and the profile or layout is picked by the monitors instead.
+func Resolve(cfg *config.Config, monitors []aerospace.Monitor, labels map[int]string) (*Topology, error) {
+	topo, err := resolve(cfg, monitors, labels)
+	if err == nil || cfg.Profile == "" {
+		return topo, err
+	}
+
+	auto := *cfg
+	auto.Profile = ""
+	topo, autoErr := resolve(&auto, monitors, labels)
+	if autoErr != nil {
+		return nil, err
+	}
+	topo.Warning = fmt.Sprintf("forced profile %s doesn't fit the connected monitors, picking by monitors instead: %v", cfg.Profile, err)
+	return topo, nil
+}
+
+func resolve(cfg *config.Config, monitors []aerospace.Monitor, labels map[int]string) (*Topology, error) {
+	monitors, transient, err := splitTransient(cfg, monitors, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := cfg.ProfileFor(monitorSet(monitors))
+	layout := cfg.LayoutFor(len(monitors))
+	if profile != nil {
+		layout = profile.Layout()
+	}
+	if layout == nil {
+		return nil, fmt.Errorf("no roles configured for %d monitors", len(monitors))
+	}
+
+	monitors, points, err := arrange(cfg, monitors, labels)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMatcher(cfg, monitors, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	topo := &Topology{
+		Monitors:  monitors,
+		Points:    points,
+		Labels:    labels,
+		Transient: transient,
+		Layout:    layout,
+		Profile:   profile,
+		Generated: profile == nil && cfg.Layout(len(monitors)) == nil,
+		Docked:    m.docked,
+	}
+	if m.docked {
+		for id := range m.builtIn {
+			topo.Primary = id
+		}
+	}
+	used := make(map[int]bool)
+	for _, a := range layout.Assign {
+		monitor, rule, ok, err := m.pick(a.Monitor, used)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if profile != nil {
+				return nil, fmt.Errorf("could not identify a monitor matching %s for profile %s", a.Monitor, profile.Name)
+			}
+			return nil, fmt.Errorf("could not identify a monitor matching %s for the %d-monitor layout", a.Monitor, len(monitors))
+		}
+		used[monitor.ID] = true
+
+		roles := make([]*config.Role, 0, len(a.Roles))
+		for _, name := range a.Roles {
+			roles = append(roles, cfg.Role(name))
+		}
+		topo.Assignments = append(topo.Assignments, Assignment{
+			Monitor: monitor,
+			Rule:    rule,
+			Roles:   roles,
+			Reason:  topo.reason(a.Monitor, rule, monitor),
+		})
+	}
+
+	for _, mon := range monitors {
+		if !used[mon.ID] {
+			topo.Spare = append(topo.Spare, mon)
+		}
+	}
+
+	return topo, nil
+}
+
+// MonitorSet returns the names profiles are matched against: the permanent
+// monitors without the suffix macOS adds to identical monitors, in ID order
+func MonitorSet(cfg *config.Config, monitors []aerospace.Monitor, labels map[int]string) ([]string, error) {
+	permanent, _, err := splitTransient(cfg, monitors, labels)
+	if err != nil {
+		return nil, err
+	}
+	return monitorSet(permanent), nil
+}
+
+func monitorSet(monitors []aerospace.Monitor) []string {
+	names := make([]string, 0, len(monitors))
+	for _, mon := range monitors {
+		names = append(names, aerospace.BaseName(mon.Name))
+	}
+	return names
+}
+
+// reason explains why a monitor was picked by the requested rule
+func (t *Topology) reason(requested, matched config.Match, mon aerospace.Monitor) string {
+	if t.Generated {
+		return fmt.Sprintf("no layout for %d monitors, roles handed out left to right", len(t.Monitors))
+	}
+
+	reason := "matched " + matched.String()
+	requested.Fallback = nil
+	if requested.String() != matched.String() {
+		reason = fmt.Sprintf("no monitor matched %s, fell back to %s", requested, matched)
+	}
+	if t.Docked && mon.ID == t.Primary {
+		reason += ", standing in for the built-in display while docked"
+	}
+	return reason
+}
+
+// RolesOn returns the roles hosted by a monitor, or nil if it hosts none
+func (t *Topology) RolesOn(monitorID int) []*config.Role {
+	for _, a := range t.Assignments {
+		if a.Monitor.ID == monitorID {
+			return a.Roles
+		}
+	}
+	return nil
+}
+
+// MonitorFor returns the ID of the monitor hosting the role that owns a
+// workspace, or of the transient monitor dedicated to it
+func (t *Topology) MonitorFor(workspace string) (int, bool) {
+	for _, tr := range t.Transient {
+		if tr.Workspace == workspace {
+			return tr.Monitor.ID, true
+		}
+	}
+	for _, a := range t.Assignments {
+		for _, role := range a.Roles {
+			for _, ws := range role.Workspaces {
+				if ws == workspace {
+					return a.Monitor.ID, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,242 @@
+package topology
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+)
+
+func TestResolve(t *testing.T) {
+	monitors := []aerospace.Monitor{
+		{ID: 1, Name: "XZ272U P (2)"},
+		{ID: 2, Name: "Built-in Retina Display"},
+		{ID: 3, Name: "XZ272U P (1)"},
+	}
+
+	topo, err := Resolve(config.Default(), monitors, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	expected := map[int]string{1: "L", 2: "B", 3: "R"}
+	for id, role := range expected {
+		roles := topo.RolesOn(id)
+		if len(roles) != 1 || roles[0].Name != role {
+			t.Errorf("RolesOn(%d) = %v, expected %s", id, roles, role)
+		}
+	}
+
+	for ws, id := range map[string]int{"L3": 1, "B1": 2, "R5": 3} {
+		if got, ok := topo.MonitorFor(ws); !ok || got != id {
+			t.Errorf("MonitorFor(%s) = %d, %v, expected %d", ws, got, ok, id)
+		}
+	}
+	if _, ok := topo.MonitorFor("S1"); ok {
+		t.Errorf("MonitorFor(S1) found a monitor, expected none")
+	}
+}
+
+func TestResolveWithoutLayout(t *testing.T) {
+	monitors := []aerospace.Monitor{
+		{ID: 1, Name: "DELL U2720Q"},
+		{ID: 2, Name: "Built-in Retina Display"},
+		{ID: 3, Name: "DELL U2720Q (2)"},
+		{ID: 4, Name: "LG HDR 4K"},
+		{ID: 5, Name: "LG HDR 4K (2)"},
+	}
+
+	topo, err := Resolve(config.Default(), monitors, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	for id, role := range map[int]string{1: "L", 2: "B", 3: "R"} {
+		if roles := topo.RolesOn(id); len(roles) != 1 || roles[0].Name != role {
+			t.Errorf("RolesOn(%d) = %v, expected %s", id, roles, role)
+		}
+	}
+	if len(topo.Spare) != 2 || topo.Spare[0].ID != 4 || topo.Spare[1].ID != 5 {
+		t.Errorf("Spare = %v, expected monitors 4 and 5", topo.Spare)
+	}
+}
+
+func TestResolveDocked(t *testing.T) {
+	monitors := []aerospace.Monitor{
+		{ID: 1, Name: "DELL U2720Q"},
+		{ID: 2, Name: "LG HDR 4K", IsMain: true},
+		{ID: 3, Name: "DELL U2720Q (2)"},
+	}
+
+	tests := []struct {
+		name     string
+		primary  *config.Match
+		expected map[int]string // Roles of each monitor
+	}{
+		{
+			name:     "main display is primary",
+			expected: map[int]string{1: "L", 2: "B", 3: "R"},
+		},
+		{
+			name:     "configured primary",
+			primary:  &config.Match{Position: config.PositionRightmost},
+			expected: map[int]string{1: "L", 2: "R", 3: "B"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Docked.Primary = tt.primary
+
+			topo, err := Resolve(cfg, monitors, nil)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if !topo.Docked {
+				t.Errorf("Docked = false, expected true")
+			}
+			for id, role := range tt.expected {
+				if roles := topo.RolesOn(id); len(roles) != 1 || roles[0].Name != role {
+					t.Errorf("RolesOn(%d) = %v, expected %s", id, roles, role)
+				}
+			}
+		})
+	}
+
+	topo, err := Resolve(config.Default(), []aerospace.Monitor{{ID: 1, Name: "Built-in Retina Display"}, {ID: 2, Name: "DELL U2720Q"}}, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if topo.Docked {
+		t.Errorf("Docked = true with a built-in display")
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		monitors []aerospace.Monitor
+	}{
+		{
+			name: "no matching monitor",
+			cfg: &config.Config{
+				Roles: []config.Role{{Name: "a", Workspaces: []string{"1"}}},
+				Layouts: []config.Layout{
+					{Monitors: 1, Assign: []config.Assignment{{Monitor: config.Match{Name: "Studio"}, Roles: []string{"a"}}}},
+				},
+			},
+			monitors: []aerospace.Monitor{{ID: 1, Name: "DELL U2720Q"}},
+		},
+		{
+			name:     "no roles",
+			cfg:      &config.Config{},
+			monitors: []aerospace.Monitor{{ID: 1, Name: "DELL U2720Q"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Resolve(tt.cfg, tt.monitors, nil); err == nil {
+				t.Errorf("Resolve() error = nil, expected an error")
+			}
+		})
+	}
+}
+
+func TestReasons(t *testing.T) {
+	cfg := &config.Config{
+		Roles: []config.Role{{Name: "a", Workspaces: []string{"1"}}, {Name: "b", Workspaces: []string{"2"}}},
+		Layouts: []config.Layout{{Monitors: 2, Assign: []config.Assignment{
+			{Monitor: config.MatchBuiltIn, Roles: []string{"a"}},
+			{Monitor: config.Match{Name: "Studio", Fallback: []config.Match{config.MatchAny}}, Roles: []string{"b"}},
+		}}},
+	}
+	monitors := []aerospace.Monitor{{ID: 1, Name: "Built-in Retina Display"}, {ID: 2, Name: "DELL U2720Q"}}
+
+	topo, err := Resolve(cfg, monitors, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	expected := []string{"matched builtin", `no monitor matched name=~"Studio", fell back to any`}
+	for i, a := range topo.Assignments {
+		if a.Reason != expected[i] {
+			t.Errorf("Assignments[%d].Reason = %q, expected %q", i, a.Reason, expected[i])
+		}
+	}
+
+	topo, err = Resolve(cfg, monitors[:1], nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if !topo.Generated || !strings.Contains(topo.Assignments[0].Reason, "left to right") {
+		t.Errorf("Generated = %v with reason %q, expected a generated layout", topo.Generated, topo.Assignments[0].Reason)
+	}
+}
+
+func TestResolveProfile(t *testing.T) {
+	cfg := config.Default()
+	cfg.Profiles = []config.Profile{{
+		Name:     "home",
+		Monitors: []string{"Built-in Retina Display", "LG HDR WQHD"},
+		Assign: []config.Assignment{
+			{Monitor: config.MatchBuiltIn, Roles: []string{"B"}},
+			{Monitor: config.MatchExternal, Roles: []string{"R", "L"}},
+		},
+		Mapping: config.MappingHybrid,
+	}}
+
+	home := []aerospace.Monitor{{ID: 1, Name: "Built-in Retina Display"}, {ID: 2, Name: "LG HDR WQHD"}}
+	topo, err := Resolve(cfg, home, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if topo.Profile == nil || topo.Profile.Name != "home" || topo.Generated {
+		t.Fatalf("Resolve() picked profile %v, expected home", topo.Profile)
+	}
+	if roles := topo.RolesOn(2); len(roles) != 2 || roles[0].Name != "R" {
+		t.Errorf("RolesOn(2) = %v, expected R and L", roles)
+	}
+	if got := cfg.MappingFor(topo.Layout); got != config.MappingHybrid {
+		t.Errorf("MappingFor() = %s, expected the profile's hybrid mapping", got)
+	}
+
+	// Another desk falls back to the layout for two monitors
+	office := []aerospace.Monitor{{ID: 1, Name: "Built-in Retina Display"}, {ID: 2, Name: "DELL U2720Q"}}
+	topo, err = Resolve(cfg, office, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if topo.Profile != nil {
+		t.Errorf("Resolve() picked profile %s, expected none", topo.Profile.Name)
+	}
+	if roles := topo.RolesOn(2); len(roles) != 2 || roles[0].Name != "L" {
+		t.Errorf("RolesOn(2) = %v, expected L and R", roles)
+	}
+
+	// Unless the profile is forced
+	cfg.Profile = "home"
+	topo, err = Resolve(cfg, office, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if topo.Profile == nil || topo.Profile.Name != "home" {
+		t.Errorf("Resolve() picked profile %v, expected the forced home", topo.Profile)
+	}
+	if topo.Warning != "" {
+		t.Errorf("Warning = %q, expected none for a forced profile that fits", topo.Warning)
+	}
+
+	// A forced profile that doesn't fit is set aside rather than breaking every hotkey
+	topo, err = Resolve(cfg, office[:1], nil)
+	if err != nil {
+		t.Fatalf("Resolve() with a forced profile that doesn't fit error = %v", err)
+	}
+	if topo.Profile != nil || topo.Layout.Monitors != 1 {
+		t.Errorf("Resolve() picked profile %v, expected the layout for one monitor", topo.Profile)
+	}
+	if !strings.Contains(topo.Warning, "forced profile home doesn't fit") {
+		t.Errorf("Warning = %q, expected it to explain why home was set aside", topo.Warning)
+	}
+}
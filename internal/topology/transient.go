@@ -0,0 +1,64 @@
+package topology
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+)
+
+// Transient is a monitor that comes and goes, kept out of role assignment
+type Transient struct {
+	Monitor   aerospace.Monitor
+	Workspace string // Dedicated workspace shown on the monitor
+}
+
+// splitTransient separates the monitors matching a transient rule from the
+// permanent ones. When every monitor is transient none is treated as such, so
+// a lone projector still gets the roles.
+func splitTransient(cfg *config.Config, monitors []aerospace.Monitor, labels map[int]string) ([]aerospace.Monitor, []Transient, error) {
+	rules := cfg.TransientRules()
+	if len(rules) == 0 {
+		return monitors, nil, nil
+	}
+
+	// Transient rules can't depend on the built-in display or position, a bare matcher is enough
+	m := &matcher{monitors: monitors, labels: labels, patterns: make(map[string]*regexp.Regexp)}
+	var permanent []aerospace.Monitor
+	var transient []Transient
+	for _, mon := range monitors {
+		isTransient := false
+		for _, rule := range rules {
+			ok, err := m.matches(rule, mon)
+			if err != nil {
+				return nil, nil, fmt.Errorf("transient: %w", err)
+			}
+			if ok {
+				isTransient = true
+				break
+			}
+		}
+
+		if isTransient {
+			transient = append(transient, Transient{Monitor: mon, Workspace: cfg.TransientWorkspace(len(transient) + 1)})
+		} else {
+			permanent = append(permanent, mon)
+		}
+	}
+
+	if len(permanent) == 0 {
+		return monitors, nil, nil
+	}
+	return permanent, transient, nil
+}
+
+// TransientWorkspace returns the dedicated workspace of a transient monitor
+func (t *Topology) TransientWorkspace(monitorID int) (string, bool) {
+	for _, tr := range t.Transient {
+		if tr.Monitor.ID == monitorID {
+			return tr.Workspace, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,80 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+)
+
+func TestResolveTransient(t *testing.T) {
+	monitors := []aerospace.Monitor{
+		{ID: 1, Name: "Built-in Retina Display"},
+		{ID: 2, Name: "Sidecar Display (AirPlay)"},
+		{ID: 3, Name: "DELL U2720Q"},
+		{ID: 4, Name: "Epson Projector"},
+	}
+
+	tests := []struct {
+		name      string
+		transient config.Transient
+		labels    map[int]string
+		monitors  []aerospace.Monitor
+		layout    int            // Monitor count of the chosen layout
+		expected  map[int]string // Transient monitor IDs and their workspaces
+	}{
+		{
+			name:     "default rules",
+			monitors: monitors[:3],
+			layout:   2,
+			expected: map[int]string{2: "T"},
+		},
+		{
+			name:      "custom rules and workspace",
+			transient: config.Transient{Monitors: []config.Match{{Name: "(?i)sidecar"}, {Label: "projector"}}, Workspace: "X"},
+			labels:    map[int]string{4: "projector"},
+			monitors:  monitors,
+			layout:    2,
+			expected:  map[int]string{2: "X", 4: "X2"},
+		},
+		{
+			name:      "defaults turned off",
+			transient: config.Transient{Monitors: []config.Match{}},
+			monitors:  monitors[:3],
+			layout:    3,
+			expected:  map[int]string{},
+		},
+		{
+			name:     "lone transient monitor gets the roles",
+			monitors: monitors[1:2],
+			layout:   1,
+			expected: map[int]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Transient = tt.transient
+
+			topo, err := Resolve(cfg, tt.monitors, tt.labels)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if topo.Layout.Monitors != tt.layout {
+				t.Errorf("Resolve() picked the %d-monitor layout, expected %d", topo.Layout.Monitors, tt.layout)
+			}
+			if len(topo.Transient) != len(tt.expected) {
+				t.Fatalf("Transient = %v, expected %v", topo.Transient, tt.expected)
+			}
+			for id, workspace := range tt.expected {
+				if got, ok := topo.TransientWorkspace(id); !ok || got != workspace {
+					t.Errorf("TransientWorkspace(%d) = %s, %v, expected %s", id, got, ok, workspace)
+				}
+				if got, ok := topo.MonitorFor(workspace); !ok || got != id {
+					t.Errorf("MonitorFor(%s) = %d, %v, expected %d", workspace, got, ok, id)
+				}
+			}
+		})
+	}
+}
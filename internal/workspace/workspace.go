@@ -0,0 +1,50 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+)
+
+// Execute switches to a workspace given by alias or name. When it lives on
+// another monitor than the mouse, the policy decides whether it is focused
+// there or pulled to the monitor with the mouse; an empty policy uses the
+// configured one.
+func Execute(ctx context.Context, client *aerospace.Client, cfg *config.Config, target string, policy string) error {
+	if policy == "" {
+		policy = cfg.SwitchPolicy()
+	}
+	if policy != config.SwitchFocus && policy != config.SwitchPull {
+		return fmt.Errorf("unknown switch policy: %s (must be focus or pull)", policy)
+	}
+
+	state, err := client.Snapshot(ctx, aerospace.SnapshotOptions{})
+	if err != nil {
+		return err
+	}
+	mouseMonitorID := state.MouseMonitorID
+
+	targetWorkspace := cfg.ResolveWorkspace(target)
+	ws := state.Workspace(targetWorkspace)
+	if ws == nil {
+		return fmt.Errorf("workspace %s does not exist: %w", targetWorkspace, aerospace.ErrUnknownWorkspace)
+	}
+
+	if ws.MonitorID != mouseMonitorID && policy == config.SwitchPull {
+		fmt.Printf("Pulling workspace %s from monitor %d to monitor %d\n", targetWorkspace, ws.MonitorID, mouseMonitorID)
+		if err := client.MoveWorkspaceToMonitor(ctx, targetWorkspace, mouseMonitorID); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("Switching to workspace %s on monitor %d\n", targetWorkspace, ws.MonitorID)
+	}
+
+	// Switch to the target workspace
+	if err := client.SwitchWorkspace(ctx, targetWorkspace); err != nil {
+		return err
+	}
+
+	return nil
+}
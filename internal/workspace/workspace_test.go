@@ -0,0 +1,66 @@
+package workspace
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/aerospace/sim"
+	"github.com/Xkonti/aeromanager/internal/config"
+)
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		policy     string // Flag override, empty for the configured policy
+		configured string
+		expected   string // Focused workspace afterwards
+		monitor    int    // Monitor holding it afterwards
+	}{
+		{name: "alias on the mouse monitor", target: "chat", expected: "B2", monitor: 1},
+		{name: "name on another monitor is focused there", target: "L3", expected: "L3", monitor: 2},
+		{name: "configured pull", target: "mail", configured: config.SwitchPull, expected: "R1", monitor: 1},
+		{name: "flag overrides the configured policy", target: "mail", policy: config.SwitchFocus, configured: config.SwitchPull, expected: "R1", monitor: 2},
+		{name: "pull flag", target: "L3", policy: config.SwitchPull, expected: "L3", monitor: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Aliases = map[string]config.Alias{
+				"chat": {Role: "B", Slot: 2},
+				"mail": {Role: "R", Slot: 1},
+			}
+			cfg.Switch = tt.configured
+
+			server := sim.New(
+				sim.Monitor{Name: "Built-in Retina Display", IsMain: true, Workspaces: sim.Names("B", 5)},
+				sim.Monitor{Name: "DELL U2720Q", Workspaces: append(sim.Names("L", 5), sim.Names("R", 5)...)},
+			)
+			server.SetMouse(1)
+
+			if err := Execute(t.Context(), aerospace.NewClient(server), cfg, tt.target, tt.policy); err != nil {
+				t.Fatalf("Execute(%s) error = %v", tt.target, err)
+			}
+			if got := server.FocusedWorkspace(); got != tt.expected {
+				t.Errorf("Execute(%s) focused %q, expected %q", tt.target, got, tt.expected)
+			}
+			if got := server.MonitorOf(tt.expected); got != tt.monitor {
+				t.Errorf("Workspace %s is on monitor %d, expected %d", tt.expected, got, tt.monitor)
+			}
+		})
+	}
+}
+
+func TestExecuteUnknown(t *testing.T) {
+	server := sim.New(sim.Monitor{Name: "Built-in Retina Display", Workspaces: sim.Names("B", 5)})
+
+	err := Execute(t.Context(), aerospace.NewClient(server), config.Default(), "chat", "")
+	if !errors.Is(err, aerospace.ErrUnknownWorkspace) {
+		t.Errorf("Execute(chat) error = %v, expected ErrUnknownWorkspace", err)
+	}
+	if len(server.Log()) != 0 {
+		t.Errorf("Execute() ran %v, expected no commands", server.Log())
+	}
+}
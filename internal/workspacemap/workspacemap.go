@@ -2,127 +2,179 @@ package workspacemap
 
 import (
 	"fmt"
-	"strings"
+	"slices"
 
-	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/topology"
 )
 
-// MapWorkspaceNumber maps a workspace number (1-5 or 6-0) to a workspace name
-// based on the monitor configuration and which monitor is targeted.
-func MapWorkspaceNumber(workspaceNum int, targetMonitorID int, monitors []aerospace.Monitor) string {
-	switch len(monitors) {
-	case 1:
-		return mapForSingleMonitor(workspaceNum)
-	case 2:
-		return mapForTwoMonitors(workspaceNum, targetMonitorID, monitors)
-	case 3:
-		return mapForThreeMonitors(workspaceNum, targetMonitorID, monitors)
+// MappingStrategy turns a number key (1-5 or 6-0) on a 1-based page into a
+// workspace name
+type MappingStrategy interface {
+	Map(workspaceNum int, page int, targetMonitorID int, topo *topology.Topology) (string, error)
+	// Pages returns how many pages of workspaces the keys can reach on a monitor
+	Pages(targetMonitorID int, topo *topology.Topology) int
+}
+
+// Relative picks from the roles of the targeted monitor: 1-5 from the first
+// role, 6-0 from the second role, or wrapping onto the first one when the
+// monitor hosts a single role. Each page moves on by config.PageSize
+// workspaces, so key 3 on page 2 reaches the eighth workspace. On a
+// transient monitor every key reaches its dedicated workspace.
+type Relative struct{}
+
+// Absolute binds every key to a fixed workspace, i3-style, wherever it lives.
+// Keys holds the workspaces for keys 1-9 and 0, ten per page; when empty the
+// workspaces of the topology's roles are used in assignment order.
+type Absolute struct {
+	Keys []string
+}
+
+// Hybrid maps 1-5 relative to the targeted monitor and 6-0 absolutely
+type Hybrid struct {
+	Keys []string // Workspaces for keys 1-9 and 0, only 6-0 are used
+}
+
+// MapWorkspaceNumber maps a workspace number using the strategy configured
+// for the topology's layout
+func MapWorkspaceNumber(workspaceNum int, page int, targetMonitorID int, cfg *config.Config, topo *topology.Topology) (string, error) {
+	return Strategy(cfg, topo).Map(workspaceNum, page, targetMonitorID, topo)
+}
+
+// Strategy returns the mapping strategy configured for the topology's layout
+func Strategy(cfg *config.Config, topo *topology.Topology) MappingStrategy {
+	switch cfg.MappingFor(topo.Layout) {
+	case config.MappingAbsolute:
+		return Absolute{Keys: cfg.Keys}
+	case config.MappingHybrid:
+		return Hybrid{Keys: cfg.Keys}
 	default:
-		// Fallback to single monitor mapping
-		return mapForSingleMonitor(workspaceNum)
+		return Relative{}
 	}
 }
 
-// mapForSingleMonitor maps workspace numbers for 1-monitor setup
-// 1-5 -> L1-L5, 6-0 -> R1-R5
-func mapForSingleMonitor(num int) string {
-	if num >= 1 && num <= 5 {
-		return fmt.Sprintf("L%d", num)
+// Map implements MappingStrategy
+func (Relative) Map(workspaceNum int, page int, targetMonitorID int, topo *topology.Topology) (string, error) {
+	if workspace, ok := topo.TransientWorkspace(targetMonitorID); ok {
+		if page != 1 {
+			return "", fmt.Errorf("transient monitor %d has no page %d", targetMonitorID, page)
+		}
+		return workspace, nil
 	}
-	// 6-0 maps to R1-R5
-	if num == 0 {
-		return "R5"
+
+	roles := topo.RolesOn(targetMonitorID)
+	if len(roles) == 0 {
+		return "", fmt.Errorf("monitor %d has no workspace roles", targetMonitorID)
 	}
-	return fmt.Sprintf("R%d", num-5)
-}
 
-// mapForTwoMonitors maps workspace numbers for 2-monitor setup
-// Built-in: 1-5 -> B1-B5, 6-0 wraps to B1-B5
-// External: 1-5 -> L1-L5, 6-0 -> R1-R5
-func mapForTwoMonitors(num int, targetMonitorID int, monitors []aerospace.Monitor) string {
-	// Find if the target is the built-in monitor
-	isBuiltIn := false
-	for _, mon := range monitors {
-		if mon.ID == targetMonitorID && strings.Contains(mon.Name, "Built-in") {
-			isBuiltIn = true
-			break
-		}
+	// 0 is the tenth key
+	if workspaceNum == 0 {
+		workspaceNum = 10
 	}
 
-	if isBuiltIn {
-		// Built-in monitor: 1-5 -> B1-B5, 6-0 wraps to B1-B5
-		if num >= 1 && num <= 5 {
-			return fmt.Sprintf("B%d", num)
-		}
-		// 6-0 wraps to B1-B5
-		if num == 0 {
-			return "B5"
+	role, slot := roles[0], workspaceNum-1
+	if workspaceNum > config.PageSize {
+		slot = workspaceNum - config.PageSize - 1
+		if len(roles) > 1 {
+			role = roles[1]
 		}
-		return fmt.Sprintf("B%d", num-5)
 	}
+	slot += (page - 1) * config.PageSize
 
-	// External monitor: same as single monitor
-	return mapForSingleMonitor(num)
+	if page < 1 || slot < 0 || slot >= len(role.Workspaces) {
+		return "", fmt.Errorf("role %s has no workspace for key %d on page %d", role.Name, workspaceNum%10, page)
+	}
+	return role.Workspaces[slot], nil
 }
 
-// mapForThreeMonitors maps workspace numbers for 3-monitor setup
-// Built-in: 1-5 -> B1-B5, 6-0 wraps to B1-B5
-// Left external: 1-5 -> L1-L5, 6-0 wraps to L1-L5
-// Right external: 1-5 -> R1-R5, 6-0 wraps to R1-R5
-func mapForThreeMonitors(num int, targetMonitorID int, monitors []aerospace.Monitor) string {
-	// Find the monitor type
-	var builtInID int
-	var leftExternalID, rightExternalID int
-	var externalIDs []int
+// Pages implements MappingStrategy
+func (Relative) Pages(targetMonitorID int, topo *topology.Topology) int {
+	if _, ok := topo.TransientWorkspace(targetMonitorID); ok {
+		return 1
+	}
+	pages := 0
+	for _, role := range topo.RolesOn(targetMonitorID) {
+		pages = max(pages, (len(role.Workspaces)+config.PageSize-1)/config.PageSize)
+	}
+	return pages
+}
 
-	for _, mon := range monitors {
-		if strings.Contains(mon.Name, "Built-in") {
-			builtInID = mon.ID
-		} else {
-			externalIDs = append(externalIDs, mon.ID)
-		}
+// Map implements MappingStrategy
+func (a Absolute) Map(workspaceNum int, page int, targetMonitorID int, topo *topology.Topology) (string, error) {
+	keys := a.keys(topo)
+
+	// 0 is the tenth key
+	slot := workspaceNum - 1
+	if workspaceNum == 0 {
+		slot = 9
 	}
+	slot += (page - 1) * 10
 
-	// Determine left and right external monitors
-	if len(externalIDs) == 2 {
-		leftExternalID = externalIDs[0]
-		rightExternalID = externalIDs[1]
-		if externalIDs[1] < externalIDs[0] {
-			leftExternalID = externalIDs[1]
-			rightExternalID = externalIDs[0]
-		}
+	if page < 1 || slot < 0 || slot >= len(keys) {
+		return "", fmt.Errorf("no workspace bound to key %d on page %d", workspaceNum, page)
 	}
+	return keys[slot], nil
+}
 
-	// Map based on which monitor the target is
-	if targetMonitorID == builtInID {
-		// Built-in monitor: 1-5 -> B1-B5, 6-0 wraps to B1-B5
-		if num >= 1 && num <= 5 {
-			return fmt.Sprintf("B%d", num)
-		}
-		if num == 0 {
-			return "B5"
-		}
-		return fmt.Sprintf("B%d", num-5)
-	} else if targetMonitorID == leftExternalID {
-		// Left external: 1-5 -> L1-L5, 6-0 wraps to L1-L5
-		if num >= 1 && num <= 5 {
-			return fmt.Sprintf("L%d", num)
-		}
-		if num == 0 {
-			return "L5"
-		}
-		return fmt.Sprintf("L%d", num-5)
-	} else if targetMonitorID == rightExternalID {
-		// Right external: 1-5 -> R1-R5, 6-0 wraps to R1-R5
-		if num >= 1 && num <= 5 {
-			return fmt.Sprintf("R%d", num)
-		}
-		if num == 0 {
-			return "R5"
+// Pages implements MappingStrategy
+func (a Absolute) Pages(targetMonitorID int, topo *topology.Topology) int {
+	return (len(a.keys(topo)) + 9) / 10
+}
+
+// keys returns the configured keys, or the workspaces of the assigned roles
+// in assignment order
+func (a Absolute) keys(topo *topology.Topology) []string {
+	if len(a.Keys) > 0 {
+		return a.Keys
+	}
+	var keys []string
+	for _, assignment := range topo.Assignments {
+		for _, role := range assignment.Roles {
+			keys = append(keys, role.Workspaces...)
 		}
-		return fmt.Sprintf("R%d", num-5)
 	}
+	return keys
+}
 
-	// Fallback (should not happen)
-	return mapForSingleMonitor(num)
-}
\ No newline at end of file
+// Map implements MappingStrategy
+func (h Hybrid) Map(workspaceNum int, page int, targetMonitorID int, topo *topology.Topology) (string, error) {
+	if workspaceNum >= 1 && workspaceNum <= config.PageSize {
+		return Relative{}.Map(workspaceNum, page, targetMonitorID, topo)
+	}
+	return Absolute{Keys: h.Keys}.Map(workspaceNum, page, targetMonitorID, topo)
+}
+
+// Pages implements MappingStrategy
+func (h Hybrid) Pages(targetMonitorID int, topo *topology.Topology) int {
+	return max(Relative{}.Pages(targetMonitorID, topo), Absolute{Keys: h.Keys}.Pages(targetMonitorID, topo))
+}
+
+// Binding is a number key on a page, pressed with the mouse on a monitor
+type Binding struct {
+	MonitorID    int
+	WorkspaceNum int
+	Page         int
+}
+
+// Bindings returns every key, page and monitor combination the strategy maps
+// to the workspace, by running the strategy over all of them
+func Bindings(strategy MappingStrategy, workspace string, topo *topology.Topology) []Binding {
+	monitors := slices.Clone(topo.Monitors)
+	for _, tr := range topo.Transient {
+		monitors = append(monitors, tr.Monitor)
+	}
+
+	var bindings []Binding
+	for _, mon := range monitors {
+		pages := strategy.Pages(mon.ID, topo)
+		for page := 1; page <= pages; page++ {
+			// Keys in keyboard order, 0 last
+			for _, num := range []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 0} {
+				if name, err := strategy.Map(num, page, mon.ID, topo); err == nil && name == workspace {
+					bindings = append(bindings, Binding{MonitorID: mon.ID, WorkspaceNum: num, Page: page})
+				}
+			}
+		}
+	}
+	return bindings
+}
@@ -0,0 +1,252 @@
+package workspacemap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/topology"
+)
+
+func TestMapWorkspaceNumber(t *testing.T) {
+	single := []aerospace.Monitor{{ID: 1, Name: "Built-in Retina Display"}}
+	double := []aerospace.Monitor{{ID: 1, Name: "Built-in Retina Display"}, {ID: 2, Name: "DELL U2720Q"}}
+	triple := []aerospace.Monitor{{ID: 1, Name: "XZ272U P (2)"}, {ID: 2, Name: "Built-in Retina Display"}, {ID: 3, Name: "XZ272U P (1)"}}
+
+	tests := []struct {
+		monitors []aerospace.Monitor
+		target   int
+		num      int
+		expected string
+	}{
+		{single, 1, 1, "L1"},
+		{single, 1, 5, "L5"},
+		{single, 1, 6, "R1"},
+		{single, 1, 0, "R5"},
+		{double, 1, 3, "B3"},
+		{double, 1, 8, "B3"},
+		{double, 1, 0, "B5"},
+		{double, 2, 2, "L2"},
+		{double, 2, 9, "R4"},
+		{triple, 1, 7, "L2"},
+		{triple, 2, 4, "B4"},
+		{triple, 3, 0, "R5"},
+	}
+
+	for _, tt := range tests {
+		topo, err := topology.Resolve(config.Default(), tt.monitors, nil)
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		got, err := MapWorkspaceNumber(tt.num, 1, tt.target, config.Default(), topo)
+		if err != nil {
+			t.Errorf("MapWorkspaceNumber(%d, %d) with %d monitors error = %v", tt.num, tt.target, len(tt.monitors), err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("MapWorkspaceNumber(%d, %d) with %d monitors = %s, expected %s", tt.num, tt.target, len(tt.monitors), got, tt.expected)
+		}
+	}
+}
+
+func TestMapWorkspaceNumberShortRole(t *testing.T) {
+	cfg := &config.Config{
+		Roles: []config.Role{{Name: "main", Workspaces: []string{"web", "code"}}},
+		Layouts: []config.Layout{
+			{Monitors: 1, Assign: []config.Assignment{{Monitor: config.MatchAny, Roles: []string{"main"}}}},
+		},
+	}
+	topo, err := topology.Resolve(cfg, []aerospace.Monitor{{ID: 1, Name: "DELL U2720Q"}}, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	if got, err := MapWorkspaceNumber(7, 1, 1, cfg, topo); err != nil || got != "code" {
+		t.Errorf("MapWorkspaceNumber(7) = %q, %v, expected code", got, err)
+	}
+	if _, err := MapWorkspaceNumber(3, 1, 1, cfg, topo); err == nil {
+		t.Errorf("MapWorkspaceNumber(3) error = nil, expected an error for a missing slot")
+	}
+	if _, err := MapWorkspaceNumber(1, 1, 2, cfg, topo); err == nil {
+		t.Errorf("MapWorkspaceNumber() on an unknown monitor error = nil, expected an error")
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	monitors := []aerospace.Monitor{{ID: 1, Name: "Built-in Retina Display"}, {ID: 2, Name: "DELL U2720Q"}}
+	topo, err := topology.Resolve(config.Default(), monitors, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	keys := []string{"B1", "B2", "B3", "L1", "L2", "L3", "R1", "R2", "R3", "R4"}
+
+	tests := []struct {
+		name     string
+		strategy MappingStrategy
+		target   int
+		num      int
+		expected string
+	}{
+		{name: "relative", strategy: Relative{}, target: 1, num: 7, expected: "B2"},
+		{name: "absolute ignores the monitor", strategy: Absolute{Keys: keys}, target: 1, num: 5, expected: "L2"},
+		{name: "absolute zero key", strategy: Absolute{Keys: keys}, target: 2, num: 0, expected: "R4"},
+		{name: "absolute from topology", strategy: Absolute{}, target: 2, num: 7, expected: "L2"},
+		{name: "hybrid lower keys are relative", strategy: Hybrid{Keys: keys}, target: 2, num: 2, expected: "L2"},
+		{name: "hybrid upper keys are absolute", strategy: Hybrid{Keys: keys}, target: 2, num: 7, expected: "R1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.strategy.Map(tt.num, 1, tt.target, topo)
+			if err != nil {
+				t.Fatalf("Map(%d, %d) error = %v", tt.num, tt.target, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Map(%d, %d) = %s, expected %s", tt.num, tt.target, got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := (Absolute{Keys: keys[:3]}).Map(4, 1, 1, topo); err == nil {
+		t.Errorf("Map() of an unbound key error = nil, expected an error")
+	}
+}
+
+func TestStrategyPerLayout(t *testing.T) {
+	cfg := config.Default()
+	cfg.Mapping = config.MappingAbsolute
+	cfg.Layouts[2].Mapping = config.MappingHybrid
+
+	tests := []struct {
+		monitors []aerospace.Monitor
+		expected MappingStrategy
+	}{
+		{[]aerospace.Monitor{{ID: 1, Name: "Built-in Retina Display"}}, Absolute{}},
+		{[]aerospace.Monitor{{ID: 1, Name: "Built-in Retina Display"}, {ID: 2, Name: "A"}, {ID: 3, Name: "B"}}, Hybrid{}},
+	}
+
+	for _, tt := range tests {
+		topo, err := topology.Resolve(cfg, tt.monitors, nil)
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		if got := Strategy(cfg, topo); fmt.Sprintf("%T", got) != fmt.Sprintf("%T", tt.expected) {
+			t.Errorf("Strategy() with %d monitors = %T, expected %T", len(tt.monitors), got, tt.expected)
+		}
+	}
+}
+
+func TestPages(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{
+		"roles": [
+			{"name": "B", "pattern": "B{n}", "count": 12},
+			{"name": "L", "pattern": "L{n}", "count": 5},
+			{"name": "R", "pattern": "R{n}", "count": 8}
+		],
+		"layouts": [{"monitors": 2, "assign": [
+			{"monitor": "builtin", "roles": ["B"]},
+			{"monitor": "external", "roles": ["L", "R"]}
+		]}]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	topo, err := topology.Resolve(cfg, []aerospace.Monitor{{ID: 1, Name: "Built-in Retina Display"}, {ID: 2, Name: "DELL U2720Q"}}, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	tests := []struct {
+		target   int
+		num      int
+		page     int
+		expected string // Empty when no workspace is reachable
+	}{
+		{target: 1, num: 3, page: 2, expected: "B8"},
+		{target: 1, num: 8, page: 2, expected: "B8"},
+		{target: 1, num: 2, page: 3, expected: "B12"},
+		{target: 1, num: 3, page: 3},
+		{target: 2, num: 7, page: 2, expected: "R7"},
+		{target: 2, num: 2, page: 2},
+		{target: 1, num: 1, page: 0},
+	}
+
+	for _, tt := range tests {
+		got, err := MapWorkspaceNumber(tt.num, tt.page, tt.target, cfg, topo)
+		if tt.expected == "" {
+			if err == nil {
+				t.Errorf("MapWorkspaceNumber(%d, page %d) = %s, expected an error", tt.num, tt.page, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("MapWorkspaceNumber(%d, page %d) = %q, %v, expected %s", tt.num, tt.page, got, err, tt.expected)
+		}
+	}
+
+	if pages := (Relative{}).Pages(1, topo); pages != 3 {
+		t.Errorf("Pages(1) = %d, expected 3", pages)
+	}
+	if pages := (Relative{}).Pages(2, topo); pages != 2 {
+		t.Errorf("Pages(2) = %d, expected 2", pages)
+	}
+	if pages := (Absolute{}).Pages(1, topo); pages != 3 {
+		t.Errorf("Absolute Pages() = %d, expected 3 for 25 workspaces", pages)
+	}
+}
+
+func TestBindings(t *testing.T) {
+	monitors := []aerospace.Monitor{{ID: 1, Name: "Built-in Retina Display"}, {ID: 2, Name: "DELL U2720Q"}}
+	topo, err := topology.Resolve(config.Default(), monitors, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	tests := []struct {
+		strategy  MappingStrategy
+		workspace string
+		expected  []Binding
+	}{
+		{Relative{}, "B3", []Binding{{MonitorID: 1, WorkspaceNum: 3, Page: 1}, {MonitorID: 1, WorkspaceNum: 8, Page: 1}}},
+		{Relative{}, "R5", []Binding{{MonitorID: 2, WorkspaceNum: 0, Page: 1}}},
+		{Relative{}, "S1", nil},
+		{Absolute{Keys: []string{"B1", "L1"}}, "L1", []Binding{{MonitorID: 1, WorkspaceNum: 2, Page: 1}, {MonitorID: 2, WorkspaceNum: 2, Page: 1}}},
+	}
+
+	for _, tt := range tests {
+		got := Bindings(tt.strategy, tt.workspace, topo)
+		if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+			t.Errorf("Bindings(%T, %s) = %v, expected %v", tt.strategy, tt.workspace, got, tt.expected)
+		}
+	}
+}
+
+func TestMapTransient(t *testing.T) {
+	monitors := []aerospace.Monitor{
+		{ID: 1, Name: "Built-in Retina Display"},
+		{ID: 2, Name: "Sidecar Display (AirPlay)"},
+		{ID: 3, Name: "DELL U2720Q"},
+	}
+	topo, err := topology.Resolve(config.Default(), monitors, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	for _, num := range []int{1, 5, 6, 0} {
+		if got, err := (Relative{}).Map(num, 1, 2, topo); err != nil || got != "T" {
+			t.Errorf("Map(%d) on the transient monitor = %q, %v, expected T", num, got, err)
+		}
+	}
+	if got, err := (Relative{}).Map(1, 2, 2, topo); err == nil {
+		t.Errorf("Map(1, page 2) on the transient monitor = %q, expected an error", got)
+	}
+	if got, err := (Relative{}).Map(7, 1, 3, topo); err != nil || got != "R2" {
+		t.Errorf("Map(7) on the external monitor = %q, %v, expected R2", got, err)
+	}
+
+	bindings := Bindings(Relative{}, "T", topo)
+	if len(bindings) != 10 || bindings[0].MonitorID != 2 {
+		t.Errorf("Bindings(T) = %v, expected every key on monitor 2", bindings)
+	}
+}
@@ -1,67 +1,324 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Xkonti/aeromanager/internal/aerospace"
+	"github.com/Xkonti/aeromanager/internal/config"
+	"github.com/Xkonti/aeromanager/internal/explain"
+	"github.com/Xkonti/aeromanager/internal/hyprmonitor"
 	"github.com/Xkonti/aeromanager/internal/hyprmove"
 	"github.com/Xkonti/aeromanager/internal/hyprworkspace"
+	"github.com/Xkonti/aeromanager/internal/label"
+	"github.com/Xkonti/aeromanager/internal/page"
+	"github.com/Xkonti/aeromanager/internal/profile"
 	"github.com/Xkonti/aeromanager/internal/rearrange"
+	"github.com/Xkonti/aeromanager/internal/simulate"
+	"github.com/Xkonti/aeromanager/internal/store"
+	"github.com/Xkonti/aeromanager/internal/workspace"
 )
 
 func main() {
 	if len(os.Args) < 2 {
 		fmt.Println("Usage: aeromanager <command> [args]")
 		fmt.Println("Commands:")
-		fmt.Println("  rearrange            - Rearrange workspaces based on monitor setup")
-		fmt.Println("  hyprworkspace <num>  - Switch workspace based on cursor position (num: 1-5 or 6-0)")
-		fmt.Println("  hyprmove [num]       - Move focused window to workspace on mouse monitor (num: 1-5 or 6-0, or omit for visible workspace)")
+		fmt.Println("  rearrange [--dry-run [--json]]")
+		fmt.Println("                              - Rearrange workspaces based on monitor setup, or only print the plan as text or JSON")
+		fmt.Println("  hyprworkspace <num> [page]  - Switch workspace based on cursor position (num: 1-5 or 6-0, page defaults to the monitor's current page)")
+		fmt.Println("  hyprmove [num [page]]       - Move focused window to workspace on mouse monitor (num: 1-5 or 6-0, or omit for visible workspace)")
+		fmt.Println("  hyprmonitor <direction>     - Focus the monitor left, right, up or down of the mouse monitor")
+		fmt.Println("  label [<monitor id> <label>]")
+		fmt.Println("                              - List monitor labels, or label the physical monitor behind an ID (\"\" forgets it)")
+		fmt.Println("  page <num|next|prev>        - Change the current page of the monitor with the mouse")
+		fmt.Println("  profile [use <name>|auto|capture <name>]")
+		fmt.Println("                              - List profiles and the active one, force a profile, go back to picking it by monitors,")
+		fmt.Println("                                or save the current arrangement as a profile")
+		fmt.Println("  explain <num|name> [page]   - Show how monitors get their roles and which keys reach a workspace")
+		fmt.Println("  simulate --monitors <names> [--mouse id] [--main id]")
+		fmt.Println("                              - Print the rearrange plan and key grid for comma-separated monitors, without Aerospace")
+		fmt.Println("  workspace <name> [--focus|--pull]")
+		fmt.Println("                              - Switch to a workspace by alias or name, focusing it where it is or pulling it to the mouse monitor")
 		os.Exit(1)
 	}
 
 	command := os.Args[1]
+	budget, err := commandBudget(command)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Bound the whole invocation so a hung Aerospace server can't leave us running
+	ctx, cancel := context.WithTimeout(context.Background(), budget)
+	client := newClient(ctx)
+	err = run(ctx, client, cfg, command, os.Args[2:])
+	client.Close()
+	cancel()
+
+	if errors.Is(err, aerospace.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		fmt.Fprintf(os.Stderr, "Error: timed out after %v waiting for Aerospace, the server may be hung: %v\n", budget, err)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// commandBudget returns how long a command may take, as overridden by the
+// AEROMANAGER_TIMEOUT environment variable (e.g. "5s")
+func commandBudget(command string) (time.Duration, error) {
+	if value := os.Getenv("AEROMANAGER_TIMEOUT"); value != "" {
+		budget, err := time.ParseDuration(value)
+		if err != nil || budget <= 0 {
+			return 0, fmt.Errorf("invalid AEROMANAGER_TIMEOUT: %s", value)
+		}
+		return budget, nil
+	}
+
+	// Rearranging moves many workspaces, while hotkey commands should feel instant
+	if command == "rearrange" {
+		return 15 * time.Second, nil
+	}
+	return 3 * time.Second, nil
+}
+
+// configPath returns the config file from AEROMANAGER_CONFIG or the default location
+func configPath() (string, error) {
+	if path := os.Getenv("AEROMANAGER_CONFIG"); path != "" {
+		return path, nil
+	}
+	return config.DefaultPath()
+}
+
+// loadConfig reads the config file, using the built-in configuration when
+// there is no file
+func loadConfig() (*config.Config, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	return config.Load(path)
+}
+
+// newClient connects to the Aerospace server socket, falling back to
+// spawning the aerospace CLI when the socket is not available
+func newClient(ctx context.Context) *aerospace.Client {
+	client, err := aerospace.NewSocketClient(ctx, aerospace.DefaultSocketPath())
+	if err != nil {
+		return aerospace.NewExecClient()
+	}
+	return client
+}
+
+// parsePage parses the optional page argument, 0 meaning the current page
+func parsePage(args []string) (int, error) {
+	if len(args) < 1 {
+		return 0, nil
+	}
+	page, err := strconv.Atoi(args[0])
+	if err != nil || page < 1 {
+		return 0, fmt.Errorf("invalid page: %s", args[0])
+	}
+	return page, nil
+}
+
+// openStore opens the state file from the default location. A profile forced
+// with aeromanager profile use overrides the one configured in cfg.
+func openStore(cfg *config.Config) (*store.Store, error) {
+	path, err := store.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	st, err := store.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if st.Profile != "" {
+		if cfg.FindProfile(st.Profile) == nil {
+			fmt.Fprintf(os.Stderr, "Warning: forced profile %s is no longer configured, ignoring it\n", st.Profile)
+		} else {
+			cfg.Profile = st.Profile
+		}
+	}
+	return st, nil
+}
 
+// run executes a single aeromanager command
+func run(ctx context.Context, client *aerospace.Client, cfg *config.Config, command string, args []string) error {
 	switch command {
 	case "rearrange":
-		if err := rearrange.Execute(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		flags := flag.NewFlagSet("rearrange", flag.ContinueOnError)
+		dryRun := flags.Bool("dry-run", false, "print the plan without changing anything")
+		asJSON := flags.Bool("json", false, "print the dry-run plan as JSON")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if *asJSON && !*dryRun {
+			return fmt.Errorf("rearrange --json requires --dry-run")
 		}
+		st, err := openStore(cfg)
+		if err != nil {
+			return err
+		}
+		if *dryRun {
+			return rearrange.DryRun(ctx, client, cfg, st, os.Stdout, *asJSON)
+		}
+		return rearrange.Execute(ctx, client, cfg, st, os.Stdout)
 	case "hyprworkspace":
-		if len(os.Args) < 3 {
-			fmt.Fprintf(os.Stderr, "Error: hyprworkspace requires a workspace number (1-5 or 6-0)\n")
-			os.Exit(1)
+		if len(args) < 1 {
+			return fmt.Errorf("hyprworkspace requires a workspace number (1-5 or 6-0)")
+		}
+		workspaceNum, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid workspace number: %s", args[0])
 		}
-		workspaceNum, err := strconv.Atoi(os.Args[2])
+		pageNum, err := parsePage(args[1:])
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: invalid workspace number: %s\n", os.Args[2])
-			os.Exit(1)
+			return err
 		}
-		if err := hyprworkspace.Execute(workspaceNum); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		st, err := openStore(cfg)
+		if err != nil {
+			return err
 		}
+		return hyprworkspace.Execute(ctx, client, cfg, st, workspaceNum, pageNum)
 	case "hyprmove":
-		var workspaceNum int
-		if len(os.Args) < 3 {
-			// No workspace number provided, use -1 to indicate moving to visible workspace
-			workspaceNum = -1
-		} else {
+		// No workspace number provided, use -1 to indicate moving to visible workspace
+		workspaceNum, pageNum := -1, 0
+		if len(args) >= 1 {
 			// Parse the workspace number
 			var err error
-			workspaceNum, err = strconv.Atoi(os.Args[2])
+			workspaceNum, err = strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid workspace number: %s", args[0])
+			}
+			if pageNum, err = parsePage(args[1:]); err != nil {
+				return err
+			}
+		}
+		st, err := openStore(cfg)
+		if err != nil {
+			return err
+		}
+		return hyprmove.Execute(ctx, client, cfg, st, workspaceNum, pageNum)
+	case "hyprmonitor":
+		if len(args) < 1 {
+			return fmt.Errorf("hyprmonitor requires a direction (left, right, up or down)")
+		}
+		st, err := openStore(cfg)
+		if err != nil {
+			return err
+		}
+		return hyprmonitor.Execute(ctx, client, cfg, st, args[0])
+	case "label":
+		st, err := openStore(cfg)
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			return label.List(ctx, client, st, os.Stdout)
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("label requires a monitor ID and a label")
+		}
+		monitorID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid monitor ID: %s", args[0])
+		}
+		return label.Execute(ctx, client, st, os.Stdout, monitorID, args[1])
+	case "page":
+		if len(args) < 1 {
+			return fmt.Errorf("page requires a page number, next or prev")
+		}
+		st, err := openStore(cfg)
+		if err != nil {
+			return err
+		}
+		return page.Execute(ctx, client, cfg, st, args[0])
+	case "profile":
+		st, err := openStore(cfg)
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			return profile.List(ctx, client, cfg, st, os.Stdout)
+		}
+		switch args[0] {
+		case "use":
+			if len(args) < 2 {
+				return fmt.Errorf("profile use requires a profile name")
+			}
+			return profile.Use(cfg, st, os.Stdout, args[1])
+		case "auto":
+			return profile.Auto(st, os.Stdout)
+		case "capture":
+			if len(args) < 2 {
+				return fmt.Errorf("profile capture requires a profile name")
+			}
+			path, err := configPath()
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: invalid workspace number: %s\n", os.Args[2])
-				os.Exit(1)
+				return err
 			}
+			return profile.Capture(ctx, client, cfg, st, os.Stdout, path, args[1])
+		default:
+			return fmt.Errorf("unknown profile command: %s", args[0])
 		}
-		if err := hyprmove.Execute(workspaceNum); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+	case "explain":
+		if len(args) < 1 {
+			return fmt.Errorf("explain requires a workspace number, alias or name")
 		}
+		pageNum, err := parsePage(args[1:])
+		if err != nil {
+			return err
+		}
+		st, err := openStore(cfg)
+		if err != nil {
+			return err
+		}
+		return explain.Execute(ctx, client, cfg, st, os.Stdout, args[0], pageNum)
+	case "simulate":
+		flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+		monitors := flags.String("monitors", "", "comma-separated monitor names, left to right")
+		mouse := flags.Int("mouse", 0, "ID of the monitor with the mouse (default: the main monitor)")
+		mainID := flags.Int("main", 1, "ID of the macOS main display")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if *monitors == "" {
+			return fmt.Errorf("simulate requires --monitors")
+		}
+		return simulate.Execute(ctx, cfg, os.Stdout, strings.Split(*monitors, ","), *mouse, *mainID)
+	case "workspace":
+		if len(args) < 1 {
+			return fmt.Errorf("workspace requires a workspace alias or name")
+		}
+		policy := ""
+		for _, arg := range args[1:] {
+			switch arg {
+			case "--focus":
+				policy = config.SwitchFocus
+			case "--pull":
+				policy = config.SwitchPull
+			default:
+				return fmt.Errorf("unknown workspace option: %s", arg)
+			}
+		}
+		return workspace.Execute(ctx, client, cfg, args[0], policy)
 	default:
-		fmt.Printf("Unknown command: %s\n", command)
-		os.Exit(1)
+		return fmt.Errorf("unknown command: %s", command)
 	}
 }